@@ -0,0 +1,88 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/abcum/webdriver"
+	"github.com/abcum/webdriver/webdrivertest"
+)
+
+func TestAttach(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			other, err := srv.Driver().Attach(s.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if other.ID != s.ID || len(other.CB) == 0 {
+				t.Errorf("got session %q with capabilities %v", other.ID, other.CB)
+			}
+			if title, err := other.Title(); err != nil || title != "Login" {
+				t.Errorf("Title = %q, %v, want %q", title, err, "Login")
+			}
+		})
+	}
+}
+
+func TestAttachFallback(t *testing.T) {
+	for _, d := range dialects {
+		for _, code := range []webdriver.ErrorCode{webdriver.UnknownCommand, webdriver.UnknownMethod} {
+			t.Run(name(d)+"/"+string(code), func(t *testing.T) {
+				calls := make(map[string]int)
+				srv, s := serve(t, d)
+				srv.Inject(webdrivertest.Fault{Method: "GET", Path: "/session/" + s.ID, Code: code})
+				other, err := srv.Driver(counter(calls)).Attach(s.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if other.ID != s.ID || other.CB != nil {
+					t.Errorf("got session %q with capabilities %v", other.ID, other.CB)
+				}
+				if calls["GET /session/"+s.ID+"/url"] != 1 {
+					t.Errorf("got commands %v, want the url to be fetched", calls)
+				}
+				if title, err := other.Title(); err != nil || title != "Login" {
+					t.Errorf("Title = %q, %v, want %q", title, err, "Login")
+				}
+			})
+		}
+	}
+}
+
+func TestAttachMissing(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			if _, err := srv.Driver().Attach("missing"); !errors.Is(err, webdriver.InvalidSessionID) {
+				t.Errorf("got %v, want %v", err, webdriver.InvalidSessionID)
+			}
+			// The fallback also reports that the session does not exist.
+			srv.Inject(webdrivertest.Fault{Method: "GET", Path: "/session/missing", Code: webdriver.UnknownCommand})
+			if _, err := srv.Driver().Attach("missing"); !errors.Is(err, webdriver.InvalidSessionID) {
+				t.Errorf("got %v, want %v", err, webdriver.InvalidSessionID)
+			}
+			if err := s.Delete(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := srv.Driver().Attach(s.ID); !errors.Is(err, webdriver.InvalidSessionID) {
+				t.Errorf("got %v, want %v", err, webdriver.InvalidSessionID)
+			}
+		})
+	}
+}
@@ -0,0 +1,494 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+)
+
+// PageLoadStrategy specifies when navigation commands return.
+type PageLoadStrategy string
+
+const (
+	// PageLoadNormal waits until the page has fully loaded.
+	PageLoadNormal PageLoadStrategy = "normal"
+	// PageLoadEager waits until the page has been parsed.
+	PageLoadEager PageLoadStrategy = "eager"
+	// PageLoadNone returns as soon as navigation has started.
+	PageLoadNone PageLoadStrategy = "none"
+)
+
+// PromptBehavior specifies how unexpected user prompts are handled.
+type PromptBehavior string
+
+const (
+	// PromptDismiss dismisses unexpected prompts.
+	PromptDismiss PromptBehavior = "dismiss"
+	// PromptAccept accepts unexpected prompts.
+	PromptAccept PromptBehavior = "accept"
+	// PromptDismissAndNotify dismisses unexpected prompts, and fails the command.
+	PromptDismissAndNotify PromptBehavior = "dismiss and notify"
+	// PromptAcceptAndNotify accepts unexpected prompts, and fails the command.
+	PromptAcceptAndNotify PromptBehavior = "accept and notify"
+	// PromptIgnore leaves unexpected prompts open, and fails the command.
+	PromptIgnore PromptBehavior = "ignore"
+)
+
+// ProxyType specifies how the browser connects to the network.
+type ProxyType string
+
+const (
+	// ProxyDirect connects directly, without a proxy.
+	ProxyDirect ProxyType = "direct"
+	// ProxyManual uses the proxies specified in the proxy configuration.
+	ProxyManual ProxyType = "manual"
+	// ProxyPAC uses the proxy auto-configuration file at the specified url.
+	ProxyPAC ProxyType = "pac"
+	// ProxyAutodetect detects the proxy configuration automatically.
+	ProxyAutodetect ProxyType = "autodetect"
+	// ProxySystem uses the proxy configuration of the operating system.
+	ProxySystem ProxyType = "system"
+)
+
+// Proxy specifies the proxy configuration of the browser.
+type Proxy struct {
+	Type         ProxyType `json:"proxyType"`
+	PAC          string    `json:"proxyAutoconfigUrl,omitempty"`
+	HTTP         string    `json:"httpProxy,omitempty"`
+	SSL          string    `json:"sslProxy,omitempty"`
+	FTP          string    `json:"ftpProxy,omitempty"`
+	SOCKS        string    `json:"socksProxy,omitempty"`
+	SOCKSVersion int       `json:"socksVersion,omitempty"`
+	NoProxy      []string  `json:"noProxy,omitempty"`
+}
+
+// ChromeOptions specifies options for Chrome, sent as goog:chromeOptions.
+type ChromeOptions struct {
+	// Args specifies command line arguments for the browser.
+	Args []string
+	// Prefs specifies user preferences for the browser profile.
+	Prefs map[string]interface{}
+	// Binary specifies the path of the browser executable.
+	Binary string
+	// Extensions specifies the paths of packed extensions to install.
+	Extensions []string
+	// Headless runs the browser without a visible window.
+	Headless bool
+}
+
+// FirefoxOptions specifies options for Firefox, sent as moz:firefoxOptions.
+type FirefoxOptions struct {
+	// Args specifies command line arguments for the browser.
+	Args []string
+	// Prefs specifies user preferences for the browser profile.
+	Prefs map[string]interface{}
+	// Binary specifies the path of the browser executable.
+	Binary string
+	// Extensions specifies the paths of add-ons to install once the
+	// session has been created.
+	Extensions []string
+	// Headless runs the browser without a visible window.
+	Headless bool
+}
+
+// Capabilities specifies the features requested of a new session. Fields
+// left empty are not sent. Capabilities are encoded for the protocol dialect
+// of the driver, or for both dialects when the dialect is negotiated.
+type Capabilities struct {
+	BrowserName             string
+	Version                 string
+	Platform                string
+	Proxy                   *Proxy
+	PageLoadStrategy        PageLoadStrategy
+	AcceptInsecureCerts     bool
+	UnhandledPromptBehavior PromptBehavior
+	Chrome                  *ChromeOptions
+	Firefox                 *FirefoxOptions
+	// Extra specifies additional capabilities, such as vendor extensions,
+	// which are sent unchanged.
+	Extra map[string]interface{}
+}
+
+// Set sets an additional capability, returning the capabilities for chaining.
+func (c *Capabilities) Set(key string, value interface{}) *Capabilities {
+	if c.Extra == nil {
+		c.Extra = make(map[string]interface{})
+	}
+	c.Extra[key] = value
+	return c
+}
+
+// encode encodes the capabilities for the specified dialect.
+func (c *Capabilities) encode(d Dialect) (map[string]interface{}, error) {
+
+	out := make(map[string]interface{})
+
+	if c.BrowserName != "" {
+		out["browserName"] = c.BrowserName
+	}
+
+	if c.Version != "" {
+		if d == W3C {
+			out["browserVersion"] = c.Version
+		} else {
+			out["version"] = c.Version
+		}
+	}
+
+	if c.Platform != "" {
+		if d == W3C {
+			out["platformName"] = strings.ToLower(c.Platform)
+		} else {
+			out["platform"] = strings.ToUpper(c.Platform)
+		}
+	}
+
+	if c.Proxy != nil {
+		out["proxy"] = c.Proxy.encode(d)
+	}
+
+	if c.PageLoadStrategy != "" {
+		out["pageLoadStrategy"] = c.PageLoadStrategy
+	}
+
+	if c.AcceptInsecureCerts {
+		out["acceptInsecureCerts"] = true
+		if d != W3C {
+			out["acceptSslCerts"] = true
+		}
+	}
+
+	if c.UnhandledPromptBehavior != "" {
+		if d == W3C {
+			out["unhandledPromptBehavior"] = c.UnhandledPromptBehavior
+		} else {
+			out["unexpectedAlertBehaviour"] = c.UnhandledPromptBehavior
+		}
+	}
+
+	if c.Chrome != nil {
+		opt, err := c.Chrome.encode()
+		if err != nil {
+			return nil, err
+		}
+		if d == W3C {
+			out["goog:chromeOptions"] = opt
+		} else {
+			out["chromeOptions"] = opt
+		}
+	}
+
+	if c.Firefox != nil {
+		out["moz:firefoxOptions"] = c.Firefox.encode()
+	}
+
+	for k, v := range c.Extra {
+		out[k] = v
+	}
+
+	return out, nil
+
+}
+
+// encode encodes the proxy configuration for the specified dialect.
+func (p *Proxy) encode(d Dialect) map[string]interface{} {
+
+	out := make(map[string]interface{})
+
+	if d == W3C {
+		out["proxyType"] = strings.ToLower(string(p.Type))
+	} else {
+		out["proxyType"] = strings.ToUpper(string(p.Type))
+	}
+
+	for k, v := range map[string]string{
+		"proxyAutoconfigUrl": p.PAC,
+		"httpProxy":          p.HTTP,
+		"sslProxy":           p.SSL,
+		"ftpProxy":           p.FTP,
+		"socksProxy":         p.SOCKS,
+	} {
+		if v != "" {
+			out[k] = v
+		}
+	}
+
+	if p.SOCKSVersion != 0 {
+		out["socksVersion"] = p.SOCKSVersion
+	}
+
+	if len(p.NoProxy) > 0 {
+		if d == W3C {
+			out["noProxy"] = p.NoProxy
+		} else {
+			out["noProxy"] = strings.Join(p.NoProxy, ",")
+		}
+	}
+
+	return out
+
+}
+
+// encode encodes the Chrome options, reading and encoding any extensions.
+func (o *ChromeOptions) encode() (map[string]interface{}, error) {
+
+	out := make(map[string]interface{})
+
+	args := o.Args
+
+	if o.Headless {
+		args = append(args[:len(args):len(args)], "--headless")
+	}
+
+	if len(args) > 0 {
+		out["args"] = args
+	}
+
+	if len(o.Prefs) > 0 {
+		out["prefs"] = o.Prefs
+	}
+
+	if o.Binary != "" {
+		out["binary"] = o.Binary
+	}
+
+	if len(o.Extensions) > 0 {
+		exts := make([]string, len(o.Extensions))
+		for i, file := range o.Extensions {
+			buf, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			exts[i] = base64.StdEncoding.EncodeToString(buf)
+		}
+		out["extensions"] = exts
+	}
+
+	return out, nil
+
+}
+
+// encode encodes the Firefox options. Add-ons are installed separately,
+// once the session has been created.
+func (o *FirefoxOptions) encode() map[string]interface{} {
+
+	out := make(map[string]interface{})
+
+	args := o.Args
+
+	if o.Headless {
+		args = append(args[:len(args):len(args)], "-headless")
+	}
+
+	if len(args) > 0 {
+		out["args"] = args
+	}
+
+	if len(o.Prefs) > 0 {
+		out["prefs"] = o.Prefs
+	}
+
+	if o.Binary != "" {
+		out["binary"] = o.Binary
+	}
+
+	return out
+
+}
+
+// SessionCapabilities describes the capabilities negotiated for a session.
+type SessionCapabilities struct {
+	BrowserName               string
+	BrowserVersion            string
+	Platform                  string
+	AcceptInsecureCerts       bool
+	PageLoadStrategy          PageLoadStrategy
+	UnhandledPromptBehavior   PromptBehavior
+	Proxy                     *Proxy
+	Timeouts                  Timeouts
+	SetWindowRect             bool
+	StrictFileInteractability bool
+	// Extensions contains vendor extensions, and any other capabilities
+	// not described above, as raw JSON.
+	Extensions map[string]json.RawMessage
+}
+
+// Capabilities parses the capabilities negotiated when the session was
+// created, accepting both the JSON Wire and the W3C capability names.
+func (s *Session) Capabilities() (*SessionCapabilities, error) {
+
+	buf, err := json.Marshal(s.CB)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		return nil, err
+	}
+
+	out := &SessionCapabilities{Extensions: make(map[string]json.RawMessage)}
+
+	// Legacy JSON Wire names are decoded first, so that W3C names take precedence.
+	fields := []struct {
+		key string
+		dst interface{}
+	}{
+		{"version", &out.BrowserVersion},
+		{"platform", &out.Platform},
+		{"acceptSslCerts", &out.AcceptInsecureCerts},
+		{"unexpectedAlertBehaviour", &out.UnhandledPromptBehavior},
+		{"browserName", &out.BrowserName},
+		{"browserVersion", &out.BrowserVersion},
+		{"platformName", &out.Platform},
+		{"acceptInsecureCerts", &out.AcceptInsecureCerts},
+		{"pageLoadStrategy", &out.PageLoadStrategy},
+		{"unhandledPromptBehavior", &out.UnhandledPromptBehavior},
+		{"setWindowRect", &out.SetWindowRect},
+		{"strictFileInteractability", &out.StrictFileInteractability},
+	}
+
+	for _, f := range fields {
+		v, ok := raw[f.key]
+		delete(raw, f.key)
+		if !ok || string(v) == "null" {
+			continue
+		}
+		if err := json.Unmarshal(v, f.dst); err != nil {
+			return nil, err
+		}
+	}
+
+	for k, v := range raw {
+		switch k {
+		case "proxy":
+			p, err := decodeProxy(v)
+			if err != nil {
+				return nil, err
+			}
+			out.Proxy = p
+		case "timeouts":
+			var ms millis
+			if err := json.Unmarshal(v, &ms); err != nil {
+				return nil, err
+			}
+			out.Timeouts = ms.timeouts()
+		default:
+			out.Extensions[k] = v
+		}
+	}
+
+	return out, nil
+
+}
+
+// decodeProxy decodes a proxy configuration encoded in either dialect.
+func decodeProxy(v json.RawMessage) (*Proxy, error) {
+	if string(v) == "null" {
+		return nil, nil
+	}
+	var p struct {
+		Proxy
+		NoProxy interface{} `json:"noProxy"`
+	}
+	if err := json.Unmarshal(v, &p); err != nil {
+		return nil, err
+	}
+	out := p.Proxy
+	out.Type = ProxyType(strings.ToLower(string(out.Type)))
+	switch n := p.NoProxy.(type) {
+	case string:
+		for _, h := range strings.Split(n, ",") {
+			if h = strings.TrimSpace(h); h != "" {
+				out.NoProxy = append(out.NoProxy, h)
+			}
+		}
+	case []interface{}:
+		for _, h := range n {
+			if h, ok := h.(string); ok {
+				out.NoProxy = append(out.NoProxy, h)
+			}
+		}
+	}
+	return &out, nil
+}
+
+// SessionWith creates a new WebDriver session using the specified capabilities.
+func (w *Driver) SessionWith(caps *Capabilities) (*Session, error) {
+	return w.SessionWithContext(context.Background(), caps)
+}
+
+// SessionWithContext creates a new WebDriver session using the specified
+// capabilities and context. Any Firefox add-ons are installed before the
+// session is returned.
+func (w *Driver) SessionWithContext(ctx context.Context, caps *Capabilities) (*Session, error) {
+
+	if caps == nil {
+		caps = &Capabilities{}
+	}
+
+	opt := make(map[string]interface{})
+
+	if w.dialect != W3C {
+		old, err := caps.encode(JSONWire)
+		if err != nil {
+			return nil, err
+		}
+		opt["desiredCapabilities"] = old
+	}
+
+	if w.dialect != JSONWire {
+		cur, err := caps.encode(W3C)
+		if err != nil {
+			return nil, err
+		}
+		opt["capabilities"] = map[string]interface{}{
+			"alwaysMatch": cur,
+			"firstMatch":  []interface{}{map[string]interface{}{}},
+		}
+	}
+
+	s, err := w.create(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	if caps.Firefox != nil {
+		for _, file := range caps.Firefox.Extensions {
+			if err := s.WithContext(ctx).install(file); err != nil {
+				s.WithContext(ctx).Delete()
+				return nil, err
+			}
+		}
+	}
+
+	return s, nil
+
+}
+
+// install installs a Firefox add-on into the browser, using the geckodriver extension command.
+func (s *Session) install(file string) error {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	opt := map[string]interface{}{"addon": base64.StdEncoding.EncodeToString(buf), "temporary": true}
+	_, _, err = s.wd.post(s.Context(), "/session/%s/moz/addon/install", opt, s.ID)
+	return err
+}
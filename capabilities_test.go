@@ -0,0 +1,90 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abcum/webdriver"
+	"github.com/abcum/webdriver/webdrivertest"
+)
+
+func TestSessionWith(t *testing.T) {
+	caps := &webdriver.Capabilities{
+		BrowserName:             "chrome",
+		AcceptInsecureCerts:     true,
+		PageLoadStrategy:        webdriver.PageLoadEager,
+		UnhandledPromptBehavior: webdriver.PromptAccept,
+		Proxy:                   &webdriver.Proxy{Type: webdriver.ProxyManual, HTTP: "proxy:3128", NoProxy: []string{"localhost", "example.com"}},
+		Chrome:                  &webdriver.ChromeOptions{Args: []string{"--no-sandbox"}, Headless: true},
+	}
+	caps.Set("timeouts", map[string]interface{}{"script": 1000, "implicit": nil})
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv := webdrivertest.NewUnstartedServer()
+			srv.Dialect = d
+			srv.Start()
+			defer srv.Close()
+			s, err := srv.Driver().SessionWith(caps)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.Capabilities()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.BrowserName != "chrome" || got.BrowserVersion == "" || !got.AcceptInsecureCerts {
+				t.Errorf("got capabilities %+v", got)
+			}
+			if got.PageLoadStrategy != webdriver.PageLoadEager || got.UnhandledPromptBehavior != webdriver.PromptAccept {
+				t.Errorf("got capabilities %+v", got)
+			}
+			if p := got.Proxy; p == nil || p.Type != webdriver.ProxyManual || p.HTTP != "proxy:3128" || len(p.NoProxy) != 2 {
+				t.Errorf("got proxy %+v", p)
+			}
+			if tm := got.Timeouts; tm.Script == nil || *tm.Script != time.Second || tm.Implicit != nil {
+				t.Errorf("got timeouts %+v", got.Timeouts)
+			}
+			key := "chromeOptions"
+			if d == webdriver.W3C {
+				key = "goog:chromeOptions"
+			}
+			if string(got.Extensions[key]) != `{"args":["--no-sandbox","--headless"]}` {
+				t.Errorf("got extensions %s", got.Extensions)
+			}
+		})
+	}
+}
+
+func TestCapabilitiesPrecedence(t *testing.T) {
+	s := &webdriver.Session{CB: map[string]interface{}{
+		"version":             "1.0",
+		"browserVersion":      "2.0",
+		"acceptSslCerts":      false,
+		"acceptInsecureCerts": true,
+		"platform":            "LINUX",
+		"platformName":        "linux",
+	}}
+	for i := 0; i < 100; i++ {
+		got, err := s.Capabilities()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.BrowserVersion != "2.0" || !got.AcceptInsecureCerts || got.Platform != "linux" || len(got.Extensions) != 0 {
+			t.Fatalf("got %+v, want the W3C capabilities to take precedence", got)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Command represents a single command sent to the remote end.
+type Command struct {
+	// Method is the HTTP method of the command.
+	Method string
+	// Path is the endpoint of the command, relative to the driver url.
+	Path string
+	// Session is the id of the session the command is sent to, if any.
+	Session string
+	// Payload is the JSON object sent with the command, or nil. Sensitive
+	// values, such as key strokes sent using Element.Value, are replaced by
+	// a placeholder, and are restored when the command is sent.
+	Payload map[string]interface{}
+	// Status is the HTTP status code returned by the remote end.
+	Status int
+	// Result is the raw JSON value returned by the remote end.
+	Result json.RawMessage
+	// Duration is the time taken for the remote end to respond.
+	Duration time.Duration
+	// res is the decoded response envelope.
+	res *response
+	// secret specifies which sensitive values the payload contains.
+	secret Redaction
+	// plain is the payload including any sensitive values.
+	plain map[string]interface{}
+}
+
+// payload returns the payload to send, restoring any sensitive values
+// which were hidden from the interceptors.
+func (c *Command) payload() map[string]interface{} {
+	if c.plain == nil || c.Payload == nil {
+		return c.Payload
+	}
+	out := make(map[string]interface{}, len(c.Payload))
+	for k, v := range c.Payload {
+		if p, ok := c.plain[k]; ok && v == redacted {
+			v = p
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// Invoker sends a command to the remote end, populating its result.
+type Invoker func(ctx context.Context, cmd *Command) error
+
+// Interceptor wraps the sending of every command. An interceptor can inspect
+// or modify the command before and after calling next, which continues the
+// chain, and can call next more than once, or not at all.
+type Interceptor func(ctx context.Context, cmd *Command, next Invoker) error
+
+// WithInterceptor appends interceptors to the chain wrapping every command.
+// The first interceptor in the chain is the outermost.
+func WithInterceptor(i ...Interceptor) Option {
+	return func(w *Driver) {
+		w.chain = append(w.chain, i...)
+	}
+}
+
+func intercept(i Interceptor, next Invoker) Invoker {
+	return func(ctx context.Context, cmd *Command) error {
+		return i(ctx, cmd, next)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// missing reports whether the error indicates that the element being
+// waited for is not present, or was replaced while it was being checked.
+func missing(err error) bool {
+	return errors.Is(err, NoSuchElement) || errors.Is(err, StaleElementReference)
+}
+
+// element returns a condition which is met when the element is present and
+// satisfies the check. Elements which are missing, or which become stale
+// while being checked, do not satisfy the condition.
+func element(using FindStrategy, value string, check func(e *Element) (bool, error)) Condition {
+	return func(s *Session) (bool, error) {
+		e, err := s.Element(using, value)
+		if err == nil {
+			var ok bool
+			if ok, err = check(e); err == nil {
+				return ok, nil
+			}
+		}
+		if missing(err) {
+			return false, nil
+		}
+		return false, err
+	}
+}
+
+// ElementPresent is met when an element matching the search is present within the page.
+func ElementPresent(using FindStrategy, value string) Condition {
+	return element(using, value, func(e *Element) (bool, error) {
+		return true, nil
+	})
+}
+
+// ElementVisible is met when an element matching the search is displayed.
+func ElementVisible(using FindStrategy, value string) Condition {
+	return element(using, value, (*Element).Displayed)
+}
+
+// ElementClickable is met when an element matching the search is displayed and enabled.
+func ElementClickable(using FindStrategy, value string) Condition {
+	return element(using, value, func(e *Element) (bool, error) {
+		ok, err := e.Displayed()
+		if err != nil || !ok {
+			return false, err
+		}
+		return e.Enabled()
+	})
+}
+
+// TextContains is met when the text of an element matching the search contains the text.
+func TextContains(using FindStrategy, value, text string) Condition {
+	return element(using, value, func(e *Element) (bool, error) {
+		out, err := e.Text()
+		return strings.Contains(out, text), err
+	})
+}
+
+// AttrEquals is met when the attribute of an element matching the search equals the value.
+func AttrEquals(using FindStrategy, value, name, want string) Condition {
+	return element(using, value, func(e *Element) (bool, error) {
+		out, err := e.Attr(name)
+		return out == want, err
+	})
+}
+
+// ElementCount is met when at least n elements matching the search are present within the page.
+func ElementCount(using FindStrategy, value string, n int) Condition {
+	return func(s *Session) (bool, error) {
+		out, err := s.Elements(using, value)
+		if err != nil {
+			return false, err
+		}
+		return len(out) >= n, nil
+	}
+}
+
+// ElementStale is met when the element is no longer attached to the page.
+func ElementStale(e *Element) Condition {
+	return func(s *Session) (bool, error) {
+		_, err := e.WithContext(s.Context()).Enabled()
+		if errors.Is(err, StaleElementReference) {
+			return true, nil
+		}
+		return false, err
+	}
+}
+
+// TitleIs is met when the title of the current page equals the title.
+func TitleIs(title string) Condition {
+	return func(s *Session) (bool, error) {
+		out, err := s.Title()
+		return out == title, err
+	}
+}
+
+// TitleContains is met when the title of the current page contains the text.
+func TitleContains(text string) Condition {
+	return func(s *Session) (bool, error) {
+		out, err := s.Title()
+		return strings.Contains(out, text), err
+	}
+}
+
+// TitleMatches is met when the title of the current page matches the regular expression.
+func TitleMatches(re *regexp.Regexp) Condition {
+	return func(s *Session) (bool, error) {
+		out, err := s.Title()
+		return re.MatchString(out), err
+	}
+}
+
+// UrlContains is met when the url of the current page contains the text.
+func UrlContains(text string) Condition {
+	return func(s *Session) (bool, error) {
+		out, err := s.Url()
+		return strings.Contains(out, text), err
+	}
+}
+
+// UrlMatches is met when the url of the current page matches the regular expression.
+func UrlMatches(re *regexp.Regexp) Condition {
+	return func(s *Session) (bool, error) {
+		out, err := s.Url()
+		return re.MatchString(out), err
+	}
+}
+
+// AlertPresent is met when a dialog window is displayed.
+func AlertPresent() Condition {
+	return func(s *Session) (bool, error) {
+		_, err := s.AlertText()
+		if errors.Is(err, NoSuchAlert) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/abcum/webdriver"
+	"github.com/abcum/webdriver/webdrivertest"
+)
+
+func TestConditionsMet(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			body := blank(t, srv, s)
+			later(srv, func() {
+				p := &webdrivertest.Node{Tag: "p", Text: "some text", Attrs: map[string]string{"id": "p", "class": "c"}}
+				body.Append(p, webdrivertest.Elem("p", map[string]string{"class": "c"}))
+			})
+			opts := webdriver.WaitOptions{Timeout: 2 * time.Second, Interval: 20 * time.Millisecond}
+			conds := map[string]webdriver.Condition{
+				"ElementPresent":   webdriver.ElementPresent(webdriver.FindByCss, "#p"),
+				"ElementVisible":   webdriver.ElementVisible(webdriver.FindByCss, "#p"),
+				"ElementClickable": webdriver.ElementClickable(webdriver.FindByCss, "#p"),
+				"TextContains":     webdriver.TextContains(webdriver.FindByCss, "#p", "some"),
+				"AttrEquals":       webdriver.AttrEquals(webdriver.FindByCss, "#p", "class", "c"),
+				"ElementCount":     webdriver.ElementCount(webdriver.FindByCss, ".c", 2),
+				"TitleIs":          webdriver.TitleIs("Blank"),
+				"TitleContains":    webdriver.TitleContains("lan"),
+				"TitleMatches":     webdriver.TitleMatches(regexp.MustCompile("^B")),
+				"UrlContains":      webdriver.UrlContains("/blank"),
+				"UrlMatches":       webdriver.UrlMatches(regexp.MustCompile("/blank$")),
+			}
+			for k, c := range conds {
+				if err := s.WaitUntil(context.Background(), c, opts); err != nil {
+					t.Errorf("%s: %v", k, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConditionsNotMet(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			_, s := serve(t, d)
+			conds := map[string]webdriver.Condition{
+				"ElementPresent":   webdriver.ElementPresent(webdriver.FindByCss, "#missing"),
+				"ElementVisible":   webdriver.ElementVisible(webdriver.FindByCss, "span"),
+				"ElementClickable": webdriver.ElementClickable(webdriver.FindByCss, "span"),
+				"TextContains":     webdriver.TextContains(webdriver.FindByCss, "a", "username"),
+				"AttrEquals":       webdriver.AttrEquals(webdriver.FindByCss, "form", "id", "signup"),
+				"ElementCount":     webdriver.ElementCount(webdriver.FindByCss, "input", 2),
+				"TitleIs":          webdriver.TitleIs("Logout"),
+				"TitleContains":    webdriver.TitleContains("out"),
+				"TitleMatches":     webdriver.TitleMatches(regexp.MustCompile("^out")),
+				"UrlContains":      webdriver.UrlContains("/logout"),
+				"UrlMatches":       webdriver.UrlMatches(regexp.MustCompile("/logout$")),
+				"AlertPresent":     webdriver.AlertPresent(),
+			}
+			for k, c := range conds {
+				if ok, err := c(s); ok || err != nil {
+					t.Errorf("%s = %v, %v, want false, nil", k, ok, err)
+				}
+			}
+		})
+	}
+}
+
+func TestAlertPresent(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			time.AfterFunc(100*time.Millisecond, func() { srv.Alert("Are you sure?") })
+			opts := webdriver.WaitOptions{Timeout: 2 * time.Second, Interval: 20 * time.Millisecond}
+			if err := s.WaitUntil(context.Background(), webdriver.AlertPresent(), opts); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.AcceptAlert(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestElementStale(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			body := blank(t, srv, s)
+			srv.Do(func() { body.Append(webdrivertest.Elem("p", map[string]string{"id": "p"})) })
+			e, err := s.Element(webdriver.FindByCss, "#p")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := webdriver.ElementStale(e)(s); ok || err != nil {
+				t.Fatalf("ElementStale = %v, %v, want false, nil", ok, err)
+			}
+			later(srv, func() { body.Children = nil })
+			opts := webdriver.WaitOptions{Timeout: 2 * time.Second, Interval: 20 * time.Millisecond}
+			if err := s.WaitUntil(context.Background(), webdriver.ElementStale(e), opts); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
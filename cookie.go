@@ -18,22 +18,22 @@ package webdriver
 type Cookie struct {
 	Name   string `json:"name"`
 	Value  string `json:"value"`
-	Path   string `json:"path"`
-	Domain string `json:"domain"`
+	Path   string `json:"path,omitempty"`
+	Domain string `json:"domain,omitempty"`
 	Secure bool   `json:"secure"`
-	Expiry int    `json:"expiry"`
+	Expiry int    `json:"expiry,omitempty"`
 	ws     *Session
 }
 
 // Set saves the cookie to the current session.
 func (c *Cookie) Set() error {
 	opt := map[string]interface{}{"cookie": c}
-	_, _, err := c.ws.wd.post("/session/%s/cookie", opt, c.ws.ID)
+	_, _, err := c.ws.wd.post(secret(c.ws.Context(), RedactCookies), "/session/%s/cookie", opt, c.ws.ID)
 	return err
 }
 
 // Clear removes the cookie from the current session.
 func (c *Cookie) Clear() error {
-	_, _, err := c.ws.wd.del("/session/%s/cookie/%s", c.ws.ID, c.Name)
+	_, _, err := c.ws.wd.del(c.ws.Context(), "/session/%s/cookie/%s", c.ws.ID, c.Name)
 	return err
 }
@@ -0,0 +1,49 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"testing"
+)
+
+func TestCookies(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			_, s := serve(t, d)
+			c := s.Cookie("token")
+			c.Value = "abc"
+			if err := c.Set(); err != nil {
+				t.Fatal(err)
+			}
+			all, err := s.Cookies()
+			if err != nil || len(all) != 1 || all[0].Name != "token" || all[0].Value != "abc" {
+				t.Fatalf("got cookies %v, %v", all, err)
+			}
+			if err := c.Clear(); err != nil {
+				t.Fatal(err)
+			}
+			if all, _ := s.Cookies(); len(all) != 0 {
+				t.Errorf("got cookies %v after delete", all)
+			}
+			c.Set()
+			if err := s.CookiesClear(); err != nil {
+				t.Fatal(err)
+			}
+			if all, _ := s.Cookies(); len(all) != 0 {
+				t.Errorf("got cookies %v after clear", all)
+			}
+		})
+	}
+}
@@ -15,18 +15,71 @@
 package webdriver
 
 import (
+	"context"
 	"encoding/json"
 )
 
+// webElement is the key used by the W3C protocol to identify web elements.
+const webElement = "element-6066-11e4-a52e-4f735466cecf"
+
+// submit is used to submit the enclosing form when using the W3C protocol.
+const submit = `var f = arguments[0];
+while (f && f.nodeName != 'FORM') f = f.parentNode;
+if (!f) throw Error('Element is not within a form');
+var e = f.ownerDocument.createEvent('Event');
+e.initEvent('submit', true, true);
+if (f.dispatchEvent(e)) f.submit();`
+
 // Element represents a web element within a page.
 type Element struct {
 	ID string `json:"ELEMENT"`
 	ws *Session
 }
 
+// MarshalJSON encodes the element reference for both protocol dialects.
+func (e Element) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"ELEMENT": e.ID, webElement: e.ID})
+}
+
+// UnmarshalJSON decodes an element reference from either protocol dialect.
+func (e *Element) UnmarshalJSON(data []byte) error {
+	var out map[string]string
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	if id, ok := out[webElement]; ok {
+		e.ID = id
+		return nil
+	}
+	e.ID = out["ELEMENT"]
+	return nil
+}
+
+// WithContext returns a copy of the element bound to the specified context.
+func (e *Element) WithContext(ctx context.Context) *Element {
+	return &Element{ID: e.ID, ws: e.ws.WithContext(ctx)}
+}
+
+func (e *Element) rect() (*rect, error) {
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/rect", e.ws.ID, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	var out rect
+	err = json.Unmarshal(res, &out)
+	return &out, err
+}
+
 // Size returns the size of the element.
 func (e *Element) Size() (*size, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/size", e.ws.ID, e.ID)
+	if e.ws.w3c() {
+		r, err := e.rect()
+		if err != nil {
+			return nil, err
+		}
+		return &size{Width: int(r.Width), Height: int(r.Height)}, nil
+	}
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/size", e.ws.ID, e.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +90,7 @@ func (e *Element) Size() (*size, error) {
 
 // Name returns the node name of the element.
 func (e *Element) Name() (string, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/name", e.ws.ID, e.ID)
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/name", e.ws.ID, e.ID)
 	if err != nil {
 		return "", err
 	}
@@ -48,7 +101,7 @@ func (e *Element) Name() (string, error) {
 
 // Text returns the visible text for the element.
 func (e *Element) Text() (string, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/text", e.ws.ID, e.ID)
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/text", e.ws.ID, e.ID)
 	if err != nil {
 		return "", err
 	}
@@ -59,7 +112,10 @@ func (e *Element) Text() (string, error) {
 
 // Html returns the outer html of the element.
 func (e *Element) Html() (string, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/attribute/outerHTML", e.ws.ID, e.ID)
+	if e.ws.w3c() {
+		return e.property("outerHTML")
+	}
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/attribute/outerHTML", e.ws.ID, e.ID)
 	if err != nil {
 		return "", err
 	}
@@ -70,7 +126,17 @@ func (e *Element) Html() (string, error) {
 
 // Attr returns the specified attribute value for the element.
 func (e *Element) Attr(name string) (string, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/attribute/%s", e.ws.ID, e.ID, name)
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/attribute/%s", e.ws.ID, e.ID, name)
+	if err != nil {
+		return "", err
+	}
+	var out string
+	err = json.Unmarshal(res, &out)
+	return out, err
+}
+
+func (e *Element) property(name string) (string, error) {
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/property/%s", e.ws.ID, e.ID, name)
 	if err != nil {
 		return "", err
 	}
@@ -81,7 +147,7 @@ func (e *Element) Attr(name string) (string, error) {
 
 // Css returns the specified computed css property for the element.
 func (e *Element) Css(name string) (string, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/css/%s", e.ws.ID, e.ID, name)
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/css/%s", e.ws.ID, e.ID, name)
 	if err != nil {
 		return "", err
 	}
@@ -92,7 +158,14 @@ func (e *Element) Css(name string) (string, error) {
 
 // Location returns the current location coordinates of the element.
 func (e *Element) Location() (*pos, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/location", e.ws.ID, e.ID)
+	if e.ws.w3c() {
+		r, err := e.rect()
+		if err != nil {
+			return nil, err
+		}
+		return &pos{X: int(r.X), Y: int(r.Y)}, nil
+	}
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/location", e.ws.ID, e.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -103,19 +176,22 @@ func (e *Element) Location() (*pos, error) {
 
 // Clear clears the value of the current element (must be a text input box).
 func (e *Element) Clear() error {
-	_, _, err := e.ws.wd.post("/session/%s/element/%s/clear", nil, e.ws.ID, e.ID)
+	_, _, err := e.ws.wd.post(e.ws.Context(), "/session/%s/element/%s/clear", nil, e.ws.ID, e.ID)
 	return err
 }
 
 // Click clicks on the current element.
 func (e *Element) Click() error {
-	_, _, err := e.ws.wd.post("/session/%s/element/%s/click", nil, e.ws.ID, e.ID)
+	_, _, err := e.ws.wd.post(e.ws.Context(), "/session/%s/element/%s/click", nil, e.ws.ID, e.ID)
 	return err
 }
 
 // Submit submits the current element (must be a form).
 func (e *Element) Submit() error {
-	_, _, err := e.ws.wd.post("/session/%s/element/%s/submit", nil, e.ws.ID, e.ID)
+	if e.ws.w3c() {
+		return e.ws.script(nil, submit, e)
+	}
+	_, _, err := e.ws.wd.post(e.ws.Context(), "/session/%s/element/%s/submit", nil, e.ws.ID, e.ID)
 	return err
 }
 
@@ -126,13 +202,19 @@ func (e *Element) Value(sequence string) error {
 		keys[i] = string(k)
 	}
 	opt := map[string]interface{}{"value": keys}
-	_, _, err := e.ws.wd.post("/session/%s/element/%s/value", opt, e.ws.ID, e.ID)
+	if e.ws.w3c() {
+		opt = map[string]interface{}{"text": sequence}
+	}
+	_, _, err := e.ws.wd.post(secret(e.ws.Context(), RedactKeys), "/session/%s/element/%s/value", opt, e.ws.ID, e.ID)
 	return err
 }
 
 // Equals returns true if this element is the same as another element.
 func (e *Element) Equals(o *Element) (bool, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/equal/%s", e.ws.ID, e.ID, o.ID)
+	if e.ws.w3c() {
+		return e.ID == o.ID, nil
+	}
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/equal/%s", e.ws.ID, e.ID, o.ID)
 	if err != nil {
 		return false, err
 	}
@@ -143,7 +225,7 @@ func (e *Element) Equals(o *Element) (bool, error) {
 
 // Enabled returns whether the current element is enabled or not.
 func (e *Element) Enabled() (bool, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/enabled", e.ws.ID, e.ID)
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/enabled", e.ws.ID, e.ID)
 	if err != nil {
 		return false, err
 	}
@@ -154,7 +236,7 @@ func (e *Element) Enabled() (bool, error) {
 
 // Displayed returns whether the current element is displayed or not.
 func (e *Element) Displayed() (bool, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/displayed", e.ws.ID, e.ID)
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/displayed", e.ws.ID, e.ID)
 	if err != nil {
 		return false, err
 	}
@@ -165,7 +247,7 @@ func (e *Element) Displayed() (bool, error) {
 
 // Selected returns whether the current element is selected or not.
 func (e *Element) Selected() (bool, error) {
-	_, res, err := e.ws.wd.get("/session/%s/element/%s/selected", e.ws.ID, e.ID)
+	_, res, err := e.ws.wd.get(e.ws.Context(), "/session/%s/element/%s/selected", e.ws.ID, e.ID)
 	if err != nil {
 		return false, err
 	}
@@ -176,8 +258,8 @@ func (e *Element) Selected() (bool, error) {
 
 // Element searches for a single element on the page, starting from this element.
 func (e *Element) Element(using FindStrategy, value string) (*Element, error) {
-	opt := map[string]interface{}{"using": using, "value": value}
-	_, res, err := e.ws.wd.post("/session/%s/element/%s/element", opt, e.ws.ID, e.ID)
+	opt := e.ws.locator(using, value)
+	_, res, err := e.ws.wd.post(e.ws.Context(), "/session/%s/element/%s/element", opt, e.ws.ID, e.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -189,8 +271,8 @@ func (e *Element) Element(using FindStrategy, value string) (*Element, error) {
 
 // Elements searches for multiple elements on the page, starting from this element.
 func (e *Element) Elements(using FindStrategy, value string) ([]*Element, error) {
-	opt := map[string]interface{}{"using": using, "value": value}
-	_, res, err := e.ws.wd.post("/session/%s/element/%s/elements", opt, e.ws.ID, e.ID)
+	opt := e.ws.locator(using, value)
+	_, res, err := e.ws.wd.post(e.ws.Context(), "/session/%s/element/%s/elements", opt, e.ws.ID, e.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,154 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/abcum/webdriver"
+)
+
+func TestElementLookup(t *testing.T) {
+	tests := []struct {
+		using webdriver.FindStrategy
+		value string
+		css   bool
+	}{
+		{webdriver.FindByCss, "form#login > input[name=user]", true},
+		{webdriver.FindByCss, "body .form.wide input", true},
+		{webdriver.FindById, "login", true},
+		{webdriver.FindById, "9lives", true},
+		{webdriver.FindByName, "user", true},
+		{webdriver.FindByClass, "wide", true},
+		{webdriver.FindByTagName, "input", false},
+		{webdriver.FindByLinkText, "Forgotten password", false},
+		{webdriver.FindByPartialLinkText, "password", false},
+	}
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			var using []string
+			spy := webdriver.WithInterceptor(func(ctx context.Context, cmd *webdriver.Command, next webdriver.Invoker) error {
+				if cmd.Payload != nil && cmd.Payload["using"] != nil {
+					using = append(using, fmt.Sprint(cmd.Payload["using"]))
+				}
+				return next(ctx, cmd)
+			})
+			_, s := serve(t, d, spy)
+			for _, test := range tests {
+				using = nil
+				e, err := s.Element(test.using, test.value)
+				if err != nil {
+					t.Errorf("%s %q: %v", test.using, test.value, err)
+					continue
+				}
+				if e.ID == "" {
+					t.Errorf("%s %q: empty element id", test.using, test.value)
+				}
+				want := string(test.using)
+				if d == webdriver.W3C && test.css {
+					want = "css selector"
+				}
+				if len(using) != 1 || using[0] != want {
+					t.Errorf("%s %q: sent strategy %v, want %v", test.using, test.value, using, want)
+				}
+			}
+		})
+	}
+}
+
+func TestElementNotFound(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			_, s := serve(t, d)
+			_, err := s.Element(webdriver.FindByCss, "#missing")
+			if !errors.Is(err, webdriver.NoSuchElement) {
+				t.Errorf("got %v, want %v", err, webdriver.NoSuchElement)
+			}
+			all, err := s.Elements(webdriver.FindByCss, "#missing")
+			if err != nil || len(all) != 0 {
+				t.Errorf("got %v, %v, want no elements", all, err)
+			}
+		})
+	}
+}
+
+func TestElementCommands(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			_, s := serve(t, d)
+			form, err := s.Element(webdriver.FindById, "login")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if txt, err := form.Text(); err != nil || txt != "Forgotten password" {
+				t.Errorf("got text %q, %v", txt, err)
+			}
+			if tag, err := form.Name(); err != nil || tag != "form" {
+				t.Errorf("got name %q, %v", tag, err)
+			}
+			if html, err := form.Html(); err != nil || html == "" {
+				t.Errorf("got html %q, %v", html, err)
+			}
+			if _, err := form.Size(); err != nil {
+				t.Errorf("got size error %v", err)
+			}
+			if _, err := form.Location(); err != nil {
+				t.Errorf("got location error %v", err)
+			}
+			user, err := form.Element(webdriver.FindByName, "user")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := user.Value("alice"); err != nil {
+				t.Fatal(err)
+			}
+			if v, err := user.Attr("value"); err != nil || v != "alice" {
+				t.Errorf("got value %q, %v", v, err)
+			}
+			if err := user.Clear(); err != nil {
+				t.Fatal(err)
+			}
+			if v, _ := user.Attr("value"); v != "" {
+				t.Errorf("got value %q after clear", v)
+			}
+			same, err := s.Element(webdriver.FindByName, "user")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if eq, err := user.Equals(same); err != nil || !eq {
+				t.Errorf("got equals %v, %v", eq, err)
+			}
+			span, err := form.Element(webdriver.FindByTagName, "span")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := span.Displayed(); err != nil || ok {
+				t.Errorf("got displayed %v, %v for hidden element", ok, err)
+			}
+			if err := user.Submit(); err != nil {
+				t.Errorf("got submit error %v", err)
+			}
+			if err := s.Load("http://example.com/other"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := user.Text(); !errors.Is(err, webdriver.StaleElementReference) {
+				t.Errorf("got %v, want %v", err, webdriver.StaleElementReference)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode identifies the class of an error returned by the remote end, using
+// the error codes defined by the W3C WebDriver specification. Error codes can be
+// compared against any error returned from this package using errors.Is.
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+const (
+	// ElementClickIntercepted occurs when a click is received by another element.
+	ElementClickIntercepted ErrorCode = "element click intercepted"
+	// ElementNotInteractable occurs when an element can not be interacted with.
+	ElementNotInteractable ErrorCode = "element not interactable"
+	// InsecureCertificate occurs when navigation hits an invalid TLS certificate.
+	InsecureCertificate ErrorCode = "insecure certificate"
+	// InvalidArgument occurs when the command arguments are invalid or missing.
+	InvalidArgument ErrorCode = "invalid argument"
+	// InvalidCookieDomain occurs when setting a cookie for a different domain.
+	InvalidCookieDomain ErrorCode = "invalid cookie domain"
+	// InvalidElementState occurs when an element is in a state preventing the command.
+	InvalidElementState ErrorCode = "invalid element state"
+	// InvalidSelector occurs when an element search uses an invalid selector.
+	InvalidSelector ErrorCode = "invalid selector"
+	// InvalidSessionID occurs when the session does not exist or is no longer active.
+	InvalidSessionID ErrorCode = "invalid session id"
+	// JavascriptError occurs when an executed script throws an error.
+	JavascriptError ErrorCode = "javascript error"
+	// MoveTargetOutOfBounds occurs when a pointer is moved outside of the viewport.
+	MoveTargetOutOfBounds ErrorCode = "move target out of bounds"
+	// NoSuchAlert occurs when there is no alert dialog currently open.
+	NoSuchAlert ErrorCode = "no such alert"
+	// NoSuchCookie occurs when no cookie matches the specified name.
+	NoSuchCookie ErrorCode = "no such cookie"
+	// NoSuchElement occurs when no element matches the search parameters.
+	NoSuchElement ErrorCode = "no such element"
+	// NoSuchFrame occurs when switching to a frame which does not exist.
+	NoSuchFrame ErrorCode = "no such frame"
+	// NoSuchWindow occurs when switching to a window which does not exist.
+	NoSuchWindow ErrorCode = "no such window"
+	// ScriptTimeout occurs when an executed script does not complete in time.
+	ScriptTimeout ErrorCode = "script timeout"
+	// SessionNotCreated occurs when a new session could not be created.
+	SessionNotCreated ErrorCode = "session not created"
+	// StaleElementReference occurs when an element is no longer attached to the page.
+	StaleElementReference ErrorCode = "stale element reference"
+	// Timeout occurs when an operation does not complete in time.
+	Timeout ErrorCode = "timeout"
+	// UnableToSetCookie occurs when the browser refuses to set a cookie.
+	UnableToSetCookie ErrorCode = "unable to set cookie"
+	// UnableToCaptureScreen occurs when a screenshot could not be taken.
+	UnableToCaptureScreen ErrorCode = "unable to capture screen"
+	// UnexpectedAlertOpen occurs when an alert dialog blocks the command.
+	UnexpectedAlertOpen ErrorCode = "unexpected alert open"
+	// UnknownCommand occurs when the remote end does not recognise the command.
+	UnknownCommand ErrorCode = "unknown command"
+	// UnknownError occurs when the remote end fails for an unknown reason.
+	UnknownError ErrorCode = "unknown error"
+	// UnknownMethod occurs when the command does not support the HTTP method.
+	UnknownMethod ErrorCode = "unknown method"
+	// UnsupportedOperation occurs when the remote end does not support the command.
+	UnsupportedOperation ErrorCode = "unsupported operation"
+)
+
+// statuses maps JSON Wire numeric status codes to their error codes.
+var statuses = map[int]ErrorCode{
+	6:  InvalidSessionID,
+	7:  NoSuchElement,
+	8:  NoSuchFrame,
+	9:  UnknownCommand,
+	10: StaleElementReference,
+	11: ElementNotInteractable,
+	12: InvalidElementState,
+	13: UnknownError,
+	15: InvalidElementState,
+	17: JavascriptError,
+	19: InvalidSelector,
+	21: Timeout,
+	23: NoSuchWindow,
+	24: InvalidCookieDomain,
+	25: UnableToSetCookie,
+	26: UnexpectedAlertOpen,
+	27: NoSuchAlert,
+	28: ScriptTimeout,
+	29: InvalidArgument,
+	32: InvalidSelector,
+	33: SessionNotCreated,
+	34: MoveTargetOutOfBounds,
+}
+
+// Error represents an error returned by the remote end.
+type Error struct {
+	// Code is the class of the error.
+	Code ErrorCode
+	// Status is the JSON Wire numeric status, or zero for W3C responses.
+	Status int
+	// HTTPStatus is the status code of the HTTP response.
+	HTTPStatus int
+	// Message is the human readable error message sent by the remote end.
+	Message string
+	// Stacktrace is the remote stacktrace, if one was sent.
+	Stacktrace string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return string(e.Code)
+	}
+	return string(e.Code) + ": " + e.Message
+}
+
+// Unwrap returns the error code, enabling comparisons using errors.Is.
+func (e *Error) Unwrap() error {
+	return e.Code
+}
+
+func oops(c int, obj *response, buf []byte) error {
+
+	err := &Error{Code: UnknownError, Status: obj.status(), HTTPStatus: c}
+
+	var val struct {
+		Error      string          `json:"error"`
+		Message    string          `json:"message"`
+		Stacktrace string          `json:"stacktrace"`
+		StackTrace json.RawMessage `json:"stackTrace"`
+	}
+
+	if json.Unmarshal(obj.Value, &val) != nil {
+		val.Message = strings.TrimSpace(string(buf))
+	}
+
+	err.Message = val.Message
+	err.Stacktrace = val.Stacktrace
+
+	switch {
+	case val.Error != "":
+		err.Code = ErrorCode(val.Error)
+	case statuses[err.Status] != "":
+		err.Code = statuses[err.Status]
+		err.Stacktrace = trace(val.StackTrace)
+	case c == 400:
+		err.Code = InvalidArgument
+	case c == 404:
+		err.Code = UnknownCommand
+	case c == 405:
+		err.Code = UnknownMethod
+	case c == 501:
+		err.Code = UnsupportedOperation
+	}
+
+	return err
+
+}
+
+// trace formats a JSON Wire stack trace as a string.
+func trace(data json.RawMessage) string {
+	var frames []struct {
+		File   string `json:"fileName"`
+		Class  string `json:"className"`
+		Method string `json:"methodName"`
+		Line   int    `json:"lineNumber"`
+	}
+	if json.Unmarshal(data, &frames) != nil {
+		return ""
+	}
+	var out strings.Builder
+	for _, f := range frames {
+		fmt.Fprintf(&out, "%s.%s (%s:%d)\n", f.Class, f.Method, f.File, f.Line)
+	}
+	return out.String()
+}
@@ -0,0 +1,209 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind identifies a well known driver executable, which determines the
+// command line flags used to launch it.
+type Kind int
+
+const (
+	// GenericDriver is a driver executable accepting chromedriver style flags.
+	GenericDriver Kind = iota
+	// ChromeDriver is the chromedriver executable for Chrome.
+	ChromeDriver
+	// GeckoDriver is the geckodriver executable for Firefox.
+	GeckoDriver
+	// OperaDriver is the operadriver executable for Opera.
+	OperaDriver
+	// SeleniumServer is the Selenium standalone server jar, launched using java.
+	// Selenium 3 jars are served under /wd/hub; Selenium 4 jars, and jars whose
+	// version can not be detected from their name, are launched in standalone
+	// mode and served from the root.
+	SeleniumServer
+)
+
+// jar matches the version within the name of a Selenium server jar.
+var jar = regexp.MustCompile(`^selenium-server(?:-standalone)?-(\d+(?:\.\d+)*)`)
+
+// Find searches for the executable of the first of the specified driver kinds
+// which is installed, searching for every kind if none are specified. Driver
+// executables are searched for within the PATH, and the Selenium standalone
+// server jar is searched for within the working directory and the PATH,
+// choosing the newest version found within the first matching directory.
+func Find(kinds ...Kind) (string, error) {
+
+	if len(kinds) == 0 {
+		kinds = []Kind{ChromeDriver, GeckoDriver, OperaDriver, SeleniumServer}
+	}
+
+	for _, k := range kinds {
+		switch k {
+		case ChromeDriver:
+			if exe, err := exec.LookPath("chromedriver"); err == nil {
+				return exe, nil
+			}
+		case GeckoDriver:
+			if exe, err := exec.LookPath("geckodriver"); err == nil {
+				return exe, nil
+			}
+		case OperaDriver:
+			if exe, err := exec.LookPath("operadriver"); err == nil {
+				return exe, nil
+			}
+		case SeleniumServer:
+			if _, err := exec.LookPath("java"); err != nil {
+				continue
+			}
+			dirs := append([]string{"."}, filepath.SplitList(os.Getenv("PATH"))...)
+			for _, dir := range dirs {
+				if jars, _ := filepath.Glob(filepath.Join(dir, "selenium-server*.jar")); len(jars) > 0 {
+					sort.Slice(jars, func(i, j int) bool {
+						return newer(version(jars[i]), version(jars[j]))
+					})
+					return filepath.Abs(jars[0])
+				}
+			}
+		}
+	}
+
+	return "", errors.New("webdriver: no driver executable found")
+
+}
+
+// kindOf detects the kind of driver from the name of the executable.
+func kindOf(exe string) Kind {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(exe)), ".exe")
+	switch {
+	case strings.HasSuffix(name, ".jar"):
+		return SeleniumServer
+	case strings.HasPrefix(name, "chromedriver"):
+		return ChromeDriver
+	case strings.HasPrefix(name, "geckodriver"):
+		return GeckoDriver
+	case strings.HasPrefix(name, "operadriver"):
+		return OperaDriver
+	default:
+		return GenericDriver
+	}
+}
+
+// version returns the version of a Selenium server jar, detected from its
+// name, or nil if the name does not contain a version.
+func version(file string) []int {
+	m := jar.FindStringSubmatch(strings.ToLower(filepath.Base(file)))
+	if m == nil {
+		return nil
+	}
+	var out []int
+	for _, v := range strings.Split(m[1], ".") {
+		n, _ := strconv.Atoi(v)
+		out = append(out, n)
+	}
+	return out
+}
+
+// newer reports whether version a is newer than version b.
+func newer(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] > b[i]
+		}
+	}
+	return len(a) > len(b)
+}
+
+// legacy reports whether the driver is a Selenium 3, or older, server jar,
+// which serves the remote end under /wd/hub and uses single dash flags.
+func (w *Driver) legacy() bool {
+	if w.which() != SeleniumServer {
+		return false
+	}
+	v := version(w.exe)
+	return len(v) > 0 && v[0] < 4
+}
+
+// which returns the kind of the driver executable.
+func (w *Driver) which() Kind {
+	if w.kind == GenericDriver {
+		return kindOf(w.exe)
+	}
+	return w.kind
+}
+
+// command builds the command which launches the driver on the specified port.
+func (w *Driver) command(port int) *exec.Cmd {
+
+	var exe string
+	var args []string
+
+	switch w.which() {
+	case SeleniumServer:
+		if w.legacy() {
+			exe, args = "java", []string{"-jar", w.exe, "-port", strconv.Itoa(port)}
+			if w.verbose {
+				args = append(args, "-debug")
+			}
+			if w.log != "" {
+				args = append(args, "-log", w.log)
+			}
+			break
+		}
+		exe, args = "java", []string{"-jar", w.exe, "standalone", "--port", strconv.Itoa(port)}
+		if w.verbose {
+			args = append(args, "--log-level", "FINE")
+		}
+		if w.log != "" {
+			args = append(args, "--log", w.log)
+		}
+	case GeckoDriver:
+		exe, args = w.exe, []string{"--port", strconv.Itoa(port)}
+		if w.verbose {
+			args = append(args, "-vv")
+		}
+	default:
+		exe, args = w.exe, []string{"--port=" + strconv.Itoa(port)}
+		if w.verbose {
+			args = append(args, "--verbose")
+		}
+		if w.log != "" {
+			args = append(args, "--log-path="+w.log)
+		}
+		if len(w.ips) > 0 {
+			args = append(args, "--allowed-ips="+strings.Join(w.ips, ","))
+		}
+	}
+
+	cmd := exec.Command(exe, append(args, w.args...)...)
+
+	cmd.Dir = w.dir
+
+	if len(w.env) > 0 {
+		cmd.Env = append(os.Environ(), w.env...)
+	}
+
+	return cmd
+
+}
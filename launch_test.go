@@ -0,0 +1,58 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSeleniumCommand(t *testing.T) {
+	tests := []struct {
+		jar  string
+		args []string
+		url  string
+	}{
+		{"/opt/selenium-server-standalone-3.141.59.jar", []string{"java", "-jar", "/opt/selenium-server-standalone-3.141.59.jar", "-port", "4444", "-debug"}, "http://127.0.0.1:4444/wd/hub"},
+		{"/opt/selenium-server-4.21.0.jar", []string{"java", "-jar", "/opt/selenium-server-4.21.0.jar", "standalone", "--port", "4444", "--log-level", "FINE"}, "http://127.0.0.1:4444"},
+		{"/opt/selenium-server.jar", []string{"java", "-jar", "/opt/selenium-server.jar", "standalone", "--port", "4444", "--log-level", "FINE"}, "http://127.0.0.1:4444"},
+	}
+	for _, test := range tests {
+		w := NewDriver("http://127.0.0.1:4444", test.jar, WithVerbose())
+		port, err := w.port()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.URL() != test.url {
+			t.Errorf("%s: got url %s, want %s", test.jar, w.URL(), test.url)
+		}
+		if cmd := w.command(port); !reflect.DeepEqual(cmd.Args, test.args) {
+			t.Errorf("%s: got args %q, want %q", test.jar, cmd.Args, test.args)
+		}
+	}
+}
+
+func TestSeleniumVersion(t *testing.T) {
+	jars := []string{"selenium-server-standalone-3.141.59.jar", "selenium-server-4.9.1.jar", "selenium-server-4.21.0.jar", "selenium-server.jar"}
+	newest := jars[0]
+	for _, j := range jars[1:] {
+		if newer(version(j), version(newest)) {
+			newest = j
+		}
+	}
+	if newest != "selenium-server-4.21.0.jar" {
+		t.Errorf("got newest %s, want selenium-server-4.21.0.jar", newest)
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// relocate specifies how many times a locator finds its element again after
+// the element becomes stale.
+const relocate = 3
+
+// Predicate reports whether an element should be selected by a locator.
+type Predicate func(e *Element) (bool, error)
+
+// HasText returns a predicate which selects elements whose visible text
+// contains the text.
+func HasText(text string) Predicate {
+	return func(e *Element) (bool, error) {
+		out, err := e.Text()
+		return strings.Contains(out, text), err
+	}
+}
+
+// Locator finds an element using a search strategy, optionally within the
+// element found by a parent locator. Unlike an Element, a Locator finds the
+// element again each time it is used, and retries commands which fail
+// because the element was replaced by the page.
+type Locator struct {
+	ws      *Session
+	parent  *Locator
+	using   FindStrategy
+	value   string
+	index   int
+	filters []Predicate
+}
+
+// Locate returns a locator for the first element within the current page
+// matching the search. The element is not searched for until it is used.
+func (s *Session) Locate(using FindStrategy, value string) *Locator {
+	return &Locator{ws: s, using: using, value: value}
+}
+
+// WithContext returns a copy of the locator bound to the specified context.
+func (l *Locator) WithContext(ctx context.Context) *Locator {
+	out := *l
+	out.ws = l.ws.WithContext(ctx)
+	if l.parent != nil {
+		out.parent = l.parent.WithContext(ctx)
+	}
+	return &out
+}
+
+// Locate returns a locator for the first element matching the search within
+// the element found by this locator.
+func (l *Locator) Locate(using FindStrategy, value string) *Locator {
+	return &Locator{ws: l.ws, parent: l, using: using, value: value}
+}
+
+// Nth returns a locator for the i-th element, counting from zero, of the
+// elements matching the search and any filters.
+func (l *Locator) Nth(i int) *Locator {
+	out := *l
+	out.index = i
+	return &out
+}
+
+// First returns a locator for the first element matching the search and any filters.
+func (l *Locator) First() *Locator {
+	return l.Nth(0)
+}
+
+// Filter returns a locator which only selects elements matching the search
+// which satisfy the predicate, in addition to any existing filters.
+func (l *Locator) Filter(pred Predicate) *Locator {
+	out := *l
+	out.filters = append(l.filters[:len(l.filters):len(l.filters)], pred)
+	return &out
+}
+
+// Find searches for the element, within the element found by the parent
+// locator, if any.
+func (l *Locator) Find() (*Element, error) {
+
+	if l.index == 0 && len(l.filters) == 0 {
+		if l.parent == nil {
+			return l.ws.Element(l.using, l.value)
+		}
+		p, err := l.parent.Find()
+		if err != nil {
+			return nil, err
+		}
+		return p.Element(l.using, l.value)
+	}
+
+	all, err := l.all()
+	if err != nil {
+		return nil, err
+	}
+
+	if l.index < 0 || l.index >= len(all) {
+		return nil, &Error{Code: NoSuchElement, Message: fmt.Sprintf("found %d elements using %s %q, wanted element %d", len(all), l.using, l.value, l.index)}
+	}
+
+	return all[l.index], nil
+
+}
+
+// FindAll searches for every element matching the search and any filters,
+// within the element found by the parent locator, if any, searching again
+// if an element becomes stale. The index set using Nth is ignored.
+func (l *Locator) FindAll() (out []*Element, err error) {
+	for i := 0; i < relocate; i++ {
+		if out, err = l.all(); !errors.Is(err, StaleElementReference) {
+			return
+		}
+	}
+	return
+}
+
+// all searches for every element matching the search and any filters.
+func (l *Locator) all() ([]*Element, error) {
+
+	var all []*Element
+	var err error
+
+	if l.parent == nil {
+		all, err = l.ws.Elements(l.using, l.value)
+	} else {
+		var p *Element
+		if p, err = l.parent.Find(); err == nil {
+			all, err = p.Elements(l.using, l.value)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := all[:0]
+
+	for _, e := range all {
+		ok := true
+		for _, f := range l.filters {
+			if ok, err = f(e); err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+		}
+		if ok {
+			out = append(out, e)
+		}
+	}
+
+	return out, nil
+
+}
+
+// do finds the element and calls fn with it, finding the element again and
+// retrying if it becomes stale.
+func (l *Locator) do(fn func(e *Element) error) error {
+	var err error
+	for i := 0; i < relocate; i++ {
+		var e *Element
+		if e, err = l.Find(); err == nil {
+			err = fn(e)
+		}
+		if !errors.Is(err, StaleElementReference) {
+			return err
+		}
+	}
+	return err
+}
+
+// Name returns the node name of the element.
+func (l *Locator) Name() (out string, err error) {
+	err = l.do(func(e *Element) (err error) {
+		out, err = e.Name()
+		return
+	})
+	return
+}
+
+// Text returns the visible text for the element.
+func (l *Locator) Text() (out string, err error) {
+	err = l.do(func(e *Element) (err error) {
+		out, err = e.Text()
+		return
+	})
+	return
+}
+
+// Html returns the outer html of the element.
+func (l *Locator) Html() (out string, err error) {
+	err = l.do(func(e *Element) (err error) {
+		out, err = e.Html()
+		return
+	})
+	return
+}
+
+// Attr returns the specified attribute value for the element.
+func (l *Locator) Attr(name string) (out string, err error) {
+	err = l.do(func(e *Element) (err error) {
+		out, err = e.Attr(name)
+		return
+	})
+	return
+}
+
+// Css returns the specified computed css property for the element.
+func (l *Locator) Css(name string) (out string, err error) {
+	err = l.do(func(e *Element) (err error) {
+		out, err = e.Css(name)
+		return
+	})
+	return
+}
+
+// Size returns the size of the element.
+func (l *Locator) Size() (out *size, err error) {
+	err = l.do(func(e *Element) (err error) {
+		out, err = e.Size()
+		return
+	})
+	return
+}
+
+// Location returns the current location coordinates of the element.
+func (l *Locator) Location() (out *pos, err error) {
+	err = l.do(func(e *Element) (err error) {
+		out, err = e.Location()
+		return
+	})
+	return
+}
+
+// Enabled returns whether the element is enabled or not.
+func (l *Locator) Enabled() (out bool, err error) {
+	err = l.do(func(e *Element) (err error) {
+		out, err = e.Enabled()
+		return
+	})
+	return
+}
+
+// Displayed returns whether the element is displayed or not.
+func (l *Locator) Displayed() (out bool, err error) {
+	err = l.do(func(e *Element) (err error) {
+		out, err = e.Displayed()
+		return
+	})
+	return
+}
+
+// Selected returns whether the element is selected or not.
+func (l *Locator) Selected() (out bool, err error) {
+	err = l.do(func(e *Element) (err error) {
+		out, err = e.Selected()
+		return
+	})
+	return
+}
+
+// Clear clears the value of the element (must be a text input box).
+func (l *Locator) Clear() error {
+	return l.do((*Element).Clear)
+}
+
+// Click clicks on the element.
+func (l *Locator) Click() error {
+	return l.do((*Element).Click)
+}
+
+// Submit submits the form enclosing the element.
+func (l *Locator) Submit() error {
+	return l.do((*Element).Submit)
+}
+
+// Value sends a sequence of key strokes to the element.
+func (l *Locator) Value(sequence string) error {
+	return l.do(func(e *Element) error {
+		return e.Value(sequence)
+	})
+}
@@ -0,0 +1,155 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/abcum/webdriver"
+	"github.com/abcum/webdriver/webdrivertest"
+)
+
+// para returns a paragraph node with the specified text.
+func para(text string) *webdrivertest.Node {
+	return &webdrivertest.Node{Tag: "p", Text: text, Attrs: map[string]string{"id": "p"}}
+}
+
+func TestLocatorLazy(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			body := blank(t, srv, s)
+			l := s.Locate(webdriver.FindByCss, "#p")
+			if _, err := l.Text(); !errors.Is(err, webdriver.NoSuchElement) {
+				t.Fatalf("got %v, want %v", err, webdriver.NoSuchElement)
+			}
+			srv.Do(func() { body.Append(para("one")) })
+			if txt, err := l.Text(); err != nil || txt != "one" {
+				t.Fatalf("Text = %q, %v, want %q", txt, err, "one")
+			}
+		})
+	}
+}
+
+func TestLocatorReplaced(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			body := blank(t, srv, s)
+			srv.Do(func() { body.Append(para("one")) })
+			l := s.Locate(webdriver.FindByCss, "#p")
+			e, err := l.Find()
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv.Do(func() { body.Children = []*webdrivertest.Node{para("two")} })
+			if _, err := e.Text(); !errors.Is(err, webdriver.StaleElementReference) {
+				t.Fatalf("got %v, want %v", err, webdriver.StaleElementReference)
+			}
+			if txt, err := l.Text(); err != nil || txt != "two" {
+				t.Fatalf("Text = %q, %v, want %q", txt, err, "two")
+			}
+		})
+	}
+}
+
+func TestLocatorStaleRetry(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			l := s.Locate(webdriver.FindByCss, "form a")
+			srv.Inject(webdrivertest.Fault{Method: "GET", Path: "/text", Code: webdriver.StaleElementReference, Times: 2})
+			if txt, err := l.Text(); err != nil || txt != "Forgotten password" {
+				t.Fatalf("Text = %q, %v, want %q", txt, err, "Forgotten password")
+			}
+			// The locator gives up once the element is stale on every attempt.
+			srv.Inject(webdrivertest.Fault{Method: "POST", Path: "/click", Code: webdriver.StaleElementReference, Times: 5})
+			if err := l.Click(); !errors.Is(err, webdriver.StaleElementReference) {
+				t.Fatalf("got %v, want %v", err, webdriver.StaleElementReference)
+			}
+		})
+	}
+}
+
+// list loads a page with a form containing a list, and an input outside the form.
+func list(t *testing.T, srv *webdrivertest.Server, s *webdriver.Session) {
+	t.Helper()
+	li := func(text string) *webdrivertest.Node {
+		return &webdrivertest.Node{Tag: "li", Text: text}
+	}
+	body := blank(t, srv, s)
+	srv.Do(func() {
+		body.Append(
+			webdrivertest.Elem("input", map[string]string{"name": "user", "value": "outside"}),
+			webdrivertest.Elem("form", map[string]string{"id": "login"},
+				webdrivertest.Elem("input", map[string]string{"name": "user", "value": "inside"}),
+				webdrivertest.Elem("ul", nil, li("a"), li("bb"), li("cb")),
+			),
+		)
+	})
+}
+
+func TestLocatorChain(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			list(t, srv, s)
+			form := s.Locate(webdriver.FindByCss, "form#login")
+			if v, err := form.Locate(webdriver.FindByName, "user").Attr("value"); err != nil || v != "inside" {
+				t.Errorf("Attr = %q, %v, want %q", v, err, "inside")
+			}
+			items := form.Locate(webdriver.FindByTagName, "li")
+			for want, l := range map[string]*webdriver.Locator{
+				"a":  items.First(),
+				"cb": items.Nth(2),
+				"bb": items.Filter(webdriver.HasText("b")).First(),
+			} {
+				if v, err := l.Text(); err != nil || v != want {
+					t.Errorf("Text = %q, %v, want %q", v, err, want)
+				}
+			}
+			if _, err := items.Nth(5).Text(); !errors.Is(err, webdriver.NoSuchElement) {
+				t.Errorf("got %v, want %v", err, webdriver.NoSuchElement)
+			}
+		})
+	}
+}
+
+func TestLocatorFilter(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			list(t, srv, s)
+			items := s.Locate(webdriver.FindByTagName, "li")
+			b := items.Filter(webdriver.HasText("b"))
+			all, err := b.FindAll()
+			if err != nil || len(all) != 2 {
+				t.Fatalf("FindAll = %d elements, %v, want 2", len(all), err)
+			}
+			// Filters accumulate without modifying the locator they derive from.
+			c := b.Filter(webdriver.HasText("c"))
+			if all, err := c.FindAll(); err != nil || len(all) != 1 {
+				t.Errorf("FindAll = %d elements, %v, want 1", len(all), err)
+			}
+			if all, err := b.FindAll(); err != nil || len(all) != 2 {
+				t.Errorf("FindAll = %d elements, %v, want 2", len(all), err)
+			}
+			if _, err := b.Nth(2).Find(); !errors.Is(err, webdriver.NoSuchElement) {
+				t.Errorf("got %v, want %v", err, webdriver.NoSuchElement)
+			}
+		})
+	}
+}
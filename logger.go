@@ -0,0 +1,160 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// limit is the maximum length of a logged request or response body.
+const limit = 512
+
+// redacted replaces sensitive values within logged request bodies.
+const redacted = "[REDACTED]"
+
+// Redaction specifies which sensitive values are hidden from logged commands.
+type Redaction int
+
+const (
+	// RedactKeys hides key strokes sent using Element.Value.
+	RedactKeys Redaction = 1 << iota
+	// RedactCookies hides cookies sent using Cookie.Set.
+	RedactCookies
+	// RedactStorage hides values sent using LocalStorageSetKey and SessionStorageSetKey.
+	RedactStorage
+	// RedactNone logs every value in full.
+	RedactNone Redaction = 0
+	// RedactAll hides every sensitive value.
+	RedactAll = RedactKeys | RedactCookies | RedactStorage
+)
+
+// Record describes a single command sent to the remote end.
+type Record struct {
+	Time     time.Time
+	Session  string
+	Method   string
+	Path     string
+	Status   int
+	Duration time.Duration
+	Request  string
+	Response string
+	Err      error
+}
+
+// String formats the record as a single line of key=value pairs.
+func (r Record) String() string {
+	out := fmt.Sprintf("time=%s session=%q method=%s path=%q status=%d duration=%s",
+		r.Time.Format(time.RFC3339Nano), r.Session, r.Method, r.Path, r.Status, r.Duration)
+	if r.Request != "" {
+		out += fmt.Sprintf(" request=%q", r.Request)
+	}
+	if r.Response != "" {
+		out += fmt.Sprintf(" response=%q", r.Response)
+	}
+	if r.Err != nil {
+		out += fmt.Sprintf(" error=%q", r.Err.Error())
+	}
+	return out
+}
+
+// Logger receives a record for every command sent to the remote end.
+type Logger interface {
+	Log(r Record)
+}
+
+// LoggerFunc adapts an ordinary function to a Logger.
+type LoggerFunc func(r Record)
+
+// Log calls f(r).
+func (f LoggerFunc) Log(r Record) {
+	f(r)
+}
+
+// NewTextLogger returns a logger which writes each record as a line of text.
+func NewTextLogger(out io.Writer) Logger {
+	var mu sync.Mutex
+	return LoggerFunc(func(r Record) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintln(out, r.String())
+	})
+}
+
+// WithLogger logs every command sent to the remote end, hiding the sensitive
+// values specified by the redaction from the logged request bodies.
+func WithLogger(l Logger, r Redaction) Option {
+	return WithInterceptor(func(ctx context.Context, cmd *Command, next Invoker) error {
+		now := time.Now()
+		err := next(ctx, cmd)
+		l.Log(Record{
+			Time:     now,
+			Session:  cmd.Session,
+			Method:   cmd.Method,
+			Path:     cmd.Path,
+			Status:   cmd.Status,
+			Duration: time.Since(now),
+			Request:  truncate(redact(cmd, r)),
+			Response: truncate(cmd.Result),
+			Err:      err,
+		})
+		return err
+	})
+}
+
+type secretKey struct{}
+
+// secret marks commands sent using the context as carrying sensitive values.
+func secret(ctx context.Context, r Redaction) context.Context {
+	return context.WithValue(ctx, secretKey{}, r)
+}
+
+// redact encodes the command payload, hiding any values marked as sensitive.
+func redact(cmd *Command, r Redaction) []byte {
+	if cmd.Payload == nil {
+		return nil
+	}
+	out, _ := json.Marshal(hide(cmd.payload(), cmd.secret, r))
+	return out
+}
+
+// hide returns a copy of the payload with its sensitive values replaced, if
+// the payload was marked as carrying values which should be redacted.
+func hide(payload map[string]interface{}, marked, r Redaction) map[string]interface{} {
+	if marked&r == 0 {
+		return payload
+	}
+	out := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		switch k {
+		case "value", "text", "cookie", "args":
+			out[k] = redacted
+		default:
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func truncate(data []byte) string {
+	if len(data) > limit {
+		return string(data[:limit]) + "..."
+	}
+	return string(data)
+}
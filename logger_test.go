@@ -0,0 +1,136 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/abcum/webdriver"
+)
+
+// secrets are the sensitive values sent by the tests.
+var secrets = []string{"hunter2", "s3ss10n", "t0ken"}
+
+// sendSecrets sends sensitive values using each command which carries them,
+// checking that they reached the remote end using a separate driver.
+func sendSecrets(t *testing.T, s *webdriver.Session, check *webdriver.Driver) {
+	t.Helper()
+	e, err := s.Element(webdriver.FindByName, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Value("hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.LocalStorageSetKey("session", "s3ss10n"); err != nil {
+		t.Fatal(err)
+	}
+	c := s.Cookie("token")
+	c.Value = "t0ken"
+	if err := c.Set(); err != nil {
+		t.Fatal(err)
+	}
+	other, err := check.Attach(s.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := other.LocalStorageGetKey("session"); v != "s3ss10n" {
+		t.Errorf("got local storage value %q", v)
+	}
+	if e, _ := other.Element(webdriver.FindByName, "user"); e == nil {
+		t.Error("input not found")
+	} else if v, _ := e.Attr("value"); v != "hunter2" {
+		t.Errorf("got input value %q", v)
+	}
+	if all, _ := other.Cookies(); len(all) != 1 || all[0].Value != "t0ken" {
+		t.Errorf("got cookies %v", all)
+	}
+}
+
+// plain removes quotes and separators from logged text, so that key strokes
+// sent by JSON Wire remote ends as a list of characters can be matched.
+var plain = strings.NewReplacer(`\"`, "", `"`, "", ",", "", `\`, "").Replace
+
+func TestLoggerRedaction(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			var log bytes.Buffer
+			var seen []string
+			spy := webdriver.WithInterceptor(func(ctx context.Context, cmd *webdriver.Command, next webdriver.Invoker) error {
+				out, _ := json.Marshal(cmd.Payload)
+				seen = append(seen, string(out))
+				return next(ctx, cmd)
+			})
+			srv, s := serve(t, d, webdriver.WithLogger(webdriver.NewTextLogger(&log), webdriver.RedactAll), spy)
+			sendSecrets(t, s, srv.Driver())
+			for _, v := range secrets {
+				if strings.Contains(plain(log.String()), v) {
+					t.Errorf("logged secret %q", v)
+				}
+				if strings.Contains(plain(strings.Join(seen, "\n")), v) {
+					t.Errorf("interceptor saw secret %q", v)
+				}
+			}
+			if !strings.Contains(log.String(), "[REDACTED]") {
+				t.Error("logged no redacted values")
+			}
+		})
+	}
+}
+
+func TestLoggerRedactNone(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			var log bytes.Buffer
+			srv, s := serve(t, d, webdriver.WithLogger(webdriver.NewTextLogger(&log), webdriver.RedactNone))
+			sendSecrets(t, s, srv.Driver())
+			for _, v := range secrets {
+				if !strings.Contains(plain(log.String()), v) {
+					t.Errorf("value %q not logged", v)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggerRecords(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			var all []webdriver.Record
+			l := webdriver.LoggerFunc(func(r webdriver.Record) { all = append(all, r) })
+			_, s := serve(t, d, webdriver.WithLogger(l, webdriver.RedactAll))
+			all = nil
+			if _, err := s.Title(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Element(webdriver.FindByCss, "#missing"); err == nil {
+				t.Fatal("expected an error")
+			}
+			if len(all) != 2 {
+				t.Fatalf("got %d records, want 2", len(all))
+			}
+			if r := all[0]; r.Session != s.ID || r.Method != "GET" || r.Path != "/session/"+s.ID+"/title" || r.Status != 200 || r.Response != `"Login"` || r.Err != nil {
+				t.Errorf("got record %s", r)
+			}
+			if r := all[1]; r.Path != "/session/"+s.ID+"/element" || r.Status < 400 || !strings.Contains(r.Request, "#missing") || r.Err == nil {
+				t.Errorf("got record %s", r)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures a driver instance.
+type Option func(*Driver)
+
+// WithDialect forces the wire protocol used to talk to the remote end,
+// instead of negotiating it when each session is created.
+func WithDialect(d Dialect) Option {
+	return func(w *Driver) {
+		w.dialect = d
+	}
+}
+
+// WithKind specifies the kind of driver executable, instead of detecting
+// it from the executable name, determining the flags used to launch it.
+func WithKind(k Kind) Option {
+	return func(w *Driver) {
+		w.kind = k
+	}
+}
+
+// WithArgs specifies additional command line arguments for the driver executable.
+func WithArgs(args ...string) Option {
+	return func(w *Driver) {
+		w.args = append(w.args, args...)
+	}
+}
+
+// WithEnv specifies additional environment variables, in the form key=value,
+// for the driver executable.
+func WithEnv(env ...string) Option {
+	return func(w *Driver) {
+		w.env = append(w.env, env...)
+	}
+}
+
+// WithDir specifies the working directory of the driver executable.
+func WithDir(dir string) Option {
+	return func(w *Driver) {
+		w.dir = dir
+	}
+}
+
+// WithVerbose enables verbose logging by the driver executable.
+func WithVerbose() Option {
+	return func(w *Driver) {
+		w.verbose = true
+	}
+}
+
+// WithLogPath specifies the file the driver executable writes its log to. It
+// is ignored by drivers which do not support it, such as geckodriver, whose
+// output can instead be captured using WithOutputFile.
+func WithLogPath(file string) Option {
+	return func(w *Driver) {
+		w.log = file
+	}
+}
+
+// WithAllowedIPs specifies the remote addresses which are allowed to connect
+// to the driver executable. It is only supported by chromedriver style drivers.
+func WithAllowedIPs(ips ...string) Option {
+	return func(w *Driver) {
+		w.ips = append(w.ips, ips...)
+	}
+}
+
+// WithStartTimeout specifies how long to wait for the driver executable to become ready.
+func WithStartTimeout(d time.Duration) Option {
+	return func(w *Driver) {
+		w.timeout = d
+	}
+}
+
+// WithGracePeriod specifies how long to wait for the driver executable to exit
+// when it is stopped, before it is killed.
+func WithGracePeriod(d time.Duration) Option {
+	return func(w *Driver) {
+		w.grace = d
+	}
+}
+
+// WithSupervisor restarts the driver executable if it exits unexpectedly,
+// at most limit times, or without limit if limit is zero. If fn is not nil,
+// it is called with each event concerning the driver executable, so that
+// sessions lost when the driver exited can be recreated. Calls to Start wait
+// for any restart in progress, so fn must not call Start.
+func WithSupervisor(limit int, fn func(Event)) Option {
+	return func(w *Driver) {
+		w.supervise, w.limit, w.notify = true, limit, fn
+	}
+}
+
+// WithOutput copies the output of the driver executable to the specified writers.
+func WithOutput(out ...io.Writer) Option {
+	return func(w *Driver) {
+		w.sinks = append(w.sinks, out...)
+	}
+}
+
+// WithOutputFile appends the output of the driver executable to the specified file.
+func WithOutputFile(file string) Option {
+	return func(w *Driver) {
+		w.file = file
+	}
+}
+
+// WithOutputLines specifies how many recent lines of driver output are kept
+// in memory, for use in error reports and for retrieval using Driver.Output.
+// A negative number is treated as zero, keeping no lines.
+func WithOutputLines(n int) Option {
+	return func(w *Driver) {
+		if n < 0 {
+			n = 0
+		}
+		w.lines = n
+	}
+}
+
+// WithClient specifies the http client used to send commands to the remote
+// end. The client is copied, so that options which configure the transport
+// do not modify it. A nil client is ignored.
+func WithClient(c *http.Client) Option {
+	return func(w *Driver) {
+		if c == nil {
+			return
+		}
+		cp := *c
+		w.client, w.tr, w.err = &cp, nil, nil
+	}
+}
+
+// WithTransport specifies the http transport used to send commands to the
+// remote end. Options which configure the transport, such as WithTLSConfig,
+// configure the transport wrapped by a Recorder, and are ignored by a
+// Replayer. Commands fail if any other round tripper is configured.
+func WithTransport(t http.RoundTripper) Option {
+	return func(w *Driver) {
+		w.client.Transport, w.tr, w.err = t, nil, nil
+	}
+}
+
+// WithTLSConfig specifies the TLS configuration used when connecting to remote grids.
+func WithTLSConfig(c *tls.Config) Option {
+	return func(w *Driver) {
+		w.transport().TLSClientConfig = c
+	}
+}
+
+// WithProxy specifies the HTTP proxy used when connecting to the remote end.
+func WithProxy(u *url.URL) Option {
+	return func(w *Driver) {
+		w.transport().Proxy = http.ProxyURL(u)
+	}
+}
+
+// WithKeepAlive specifies the maximum number of idle connections kept open to
+// the remote end, and for how long they are kept. A maximum of zero disables
+// keep-alives altogether.
+func WithKeepAlive(max int, timeout time.Duration) Option {
+	return func(w *Driver) {
+		t := w.transport()
+		t.DisableKeepAlives = max == 0
+		t.MaxIdleConnsPerHost = max
+		t.IdleConnTimeout = timeout
+	}
+}
+
+// WithHeader specifies a header which is sent with every command.
+func WithHeader(key, value string) Option {
+	return func(w *Driver) {
+		w.header.Add(key, value)
+	}
+}
+
+// WithBasicAuth specifies the credentials used to authenticate with the remote end.
+func WithBasicAuth(user, pass string) Option {
+	return func(w *Driver) {
+		w.auth = url.UserPassword(user, pass)
+	}
+}
+
+// transport returns a http transport owned by the driver client, which can be
+// configured without affecting other clients. The first time it is called,
+// the current transport, or the default transport, is cloned.
+func (w *Driver) transport() *http.Transport {
+	if w.tr == nil {
+		w.client.Transport, w.tr, w.err = own(w.client.Transport)
+	}
+	return w.tr
+}
+
+// own returns a copy of the round tripper which sends requests using a clone
+// of the transport it wraps, along with the clone.
+func own(rt http.RoundTripper) (http.RoundTripper, *http.Transport, error) {
+	switch t := rt.(type) {
+	case nil:
+		c := http.DefaultTransport.(*http.Transport).Clone()
+		return c, c, nil
+	case *http.Transport:
+		c := t.Clone()
+		return c, c, nil
+	case *Recorder:
+		next, c, err := own(t.next)
+		return t.wrap(next), c, err
+	case *Replayer:
+		// Replayed exchanges are never sent, so the settings have no effect.
+		return t, &http.Transport{}, nil
+	default:
+		return t, &http.Transport{}, fmt.Errorf("webdriver: can not configure transport of type %T", rt)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abcum/webdriver"
+)
+
+// remote starts a server which responds to every command with a ready
+// status, passing each request to fn, if not nil.
+func remote(t *testing.T, tls bool, fn func(r *http.Request)) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fn != nil {
+			mu.Lock()
+			fn(r)
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json;charset=utf-8")
+		w.Write([]byte(`{"value":{"ready":true,"message":"ok"}}`))
+	})
+	srv := httptest.NewUnstartedServer(h)
+	srv.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	if tls {
+		srv.StartTLS()
+	} else {
+		srv.Start()
+	}
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// roundTripper adapts an ordinary function to a http.RoundTripper.
+type roundTripper func(r *http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestWithHeaderAndBasicAuth(t *testing.T) {
+	var hdr, user, pass string
+	srv := remote(t, false, func(r *http.Request) {
+		hdr = r.Header.Get("X-Grid-Token")
+		user, pass, _ = r.BasicAuth()
+	})
+	d := webdriver.NewDriver(srv.URL, "", webdriver.WithHeader("X-Grid-Token", "abc"), webdriver.WithBasicAuth("user", "pass"))
+	if _, err := d.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if hdr != "abc" || user != "user" || pass != "pass" {
+		t.Errorf("got header %q and credentials %q:%q", hdr, user, pass)
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	srv := remote(t, true, nil)
+	tls := srv.Client().Transport.(*http.Transport).TLSClientConfig
+	if _, err := webdriver.NewDriver(srv.URL, "").Status(); err == nil {
+		t.Fatal("expected an error from an untrusted certificate")
+	}
+	if _, err := webdriver.NewDriver(srv.URL, "", webdriver.WithTLSConfig(tls)).Status(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	var host string
+	proxy := remote(t, false, func(r *http.Request) {
+		host = r.URL.Host
+	})
+	u, _ := url.Parse(proxy.URL)
+	d := webdriver.NewDriver("http://grid.invalid:4444", "", webdriver.WithProxy(u))
+	if _, err := d.Status(); err != nil {
+		t.Fatal(err)
+	}
+	if host != "grid.invalid:4444" {
+		t.Errorf("got proxied host %q, want %q", host, "grid.invalid:4444")
+	}
+}
+
+func TestWithKeepAlive(t *testing.T) {
+	var closing []bool
+	srv := remote(t, false, func(r *http.Request) {
+		closing = append(closing, r.Close)
+	})
+	for _, max := range []int{0, 2} {
+		d := webdriver.NewDriver(srv.URL, "", webdriver.WithKeepAlive(max, time.Second))
+		if _, err := d.Status(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(closing) != 2 || !closing[0] || closing[1] {
+		t.Errorf("got connection close %v, want [true false]", closing)
+	}
+}
+
+func TestWithClientNotModified(t *testing.T) {
+	srv := remote(t, true, nil)
+	tls := srv.Client().Transport.(*http.Transport).TLSClientConfig
+	// Cloning a transport initialises it, so compare afterwards.
+	def := http.DefaultTransport.(*http.Transport)
+	def.Clone()
+	before := def.TLSClientConfig
+	own := &http.Transport{}
+	own.Clone()
+	mine := own.TLSClientConfig
+	c := &http.Client{Transport: own, Timeout: time.Minute}
+	for _, opts := range [][]webdriver.Option{
+		{webdriver.WithClient(c), webdriver.WithTLSConfig(tls)},
+		{webdriver.WithClient(nil), webdriver.WithTLSConfig(tls), webdriver.WithKeepAlive(0, 0)},
+	} {
+		if _, err := webdriver.NewDriver(srv.URL, "", opts...).Status(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.Transport != own || own.TLSClientConfig != mine {
+		t.Error("the caller's client or transport was modified")
+	}
+	if def.TLSClientConfig != before {
+		t.Error("the default transport was modified")
+	}
+}
+
+func TestWithTransportRecorder(t *testing.T) {
+	srv := remote(t, true, nil)
+	tls := srv.Client().Transport.(*http.Transport).TLSClientConfig
+	var buf bytes.Buffer
+	rec := webdriver.NewRecorder(&buf, nil)
+	for _, opts := range [][]webdriver.Option{
+		{webdriver.WithTransport(rec), webdriver.WithTLSConfig(tls)},
+		{webdriver.WithTLSConfig(tls), webdriver.WithTransport(rec), webdriver.WithTLSConfig(tls), webdriver.WithKeepAlive(1, time.Second)},
+	} {
+		if _, err := webdriver.NewDriver(srv.URL, "", opts...).Status(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 2 {
+		t.Errorf("got %d recorded exchanges, want 2", n)
+	}
+}
+
+func TestWithTransportCustom(t *testing.T) {
+	srv := remote(t, false, nil)
+	calls := 0
+	rt := roundTripper(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return http.DefaultTransport.RoundTrip(r)
+	})
+	if _, err := webdriver.NewDriver(srv.URL, "", webdriver.WithTransport(rt)).Status(); err != nil {
+		t.Fatal(err)
+	}
+	// A custom round tripper can not be configured, but must not be replaced.
+	if _, err := webdriver.NewDriver(srv.URL, "", webdriver.WithTransport(rt), webdriver.WithKeepAlive(0, 0)).Status(); err == nil {
+		t.Error("expected an error configuring a custom round tripper")
+	}
+	if calls != 1 {
+		t.Errorf("got %d calls to the round tripper, want 1", calls)
+	}
+	// Replacing the transport discards the error.
+	opts := []webdriver.Option{webdriver.WithTransport(rt), webdriver.WithKeepAlive(0, 0), webdriver.WithTransport(nil)}
+	if _, err := webdriver.NewDriver(srv.URL, "", opts...).Status(); err != nil {
+		t.Fatal(err)
+	}
+}
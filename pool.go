@@ -0,0 +1,247 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrPoolClosed is returned when requesting a session from a closed pool.
+var ErrPoolClosed = errors.New("webdriver: pool is closed")
+
+// Pool maintains a fixed number of sessions which are handed out to, and
+// returned by, concurrent callers. Sessions are reset when they are
+// returned to the pool, and replaced when they are discarded.
+type Pool struct {
+	wd       *Driver
+	desired  map[string]interface{}
+	required map[string]interface{}
+	idle     chan *Session
+	free     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+	mu       sync.Mutex
+	out      map[string]bool
+}
+
+// NewPool creates a pool of size sessions using the specified capabilities,
+// creating the sessions concurrently before returning. If any session can
+// not be created, the sessions already created are deleted.
+func NewPool(w *Driver, size int, desired, required map[string]interface{}) (*Pool, error) {
+
+	if size <= 0 {
+		return nil, fmt.Errorf("webdriver: invalid pool size %d", size)
+	}
+
+	p := &Pool{
+		wd:       w,
+		desired:  desired,
+		required: required,
+		idle:     make(chan *Session, size),
+		free:     make(chan struct{}, size),
+		done:     make(chan struct{}),
+		out:      make(map[string]bool),
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, size)
+
+	for i := 0; i < size; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := w.Session(desired, required)
+			if err != nil {
+				errs <- err
+				return
+			}
+			p.idle <- s
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		p.Close()
+		return nil, err
+	}
+
+	return p, nil
+
+}
+
+// Get returns an idle session from the pool, creating a new session if one
+// was previously discarded, or waits until a session is returned to the
+// pool. The context is used while waiting and while creating a session.
+func (p *Pool) Get(ctx context.Context) (*Session, error) {
+	select {
+	case <-p.done:
+		return nil, ErrPoolClosed
+	default:
+	}
+	select {
+	case <-p.done:
+		return nil, ErrPoolClosed
+	case s := <-p.idle:
+		return p.lend(s), nil
+	case <-p.free:
+		s, err := p.wd.SessionContext(ctx, p.desired, p.required)
+		if err != nil {
+			p.free <- struct{}{}
+			return nil, err
+		}
+		return p.lend(s), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Put resets the session and returns it to the pool. The session is closed
+// down to its first window, its cookies and storage are cleared, and it is
+// navigated to a blank page. If the session can not be reset, or the pool
+// has been closed, the session is discarded. Sessions which were not handed
+// out by the pool, or which have already been returned, are ignored.
+func (p *Pool) Put(s *Session) {
+	if !p.take(s) {
+		return
+	}
+	select {
+	case <-p.done:
+		p.discard(s)
+		return
+	default:
+	}
+	if err := reset(s); err != nil {
+		p.discard(s)
+		return
+	}
+	// The pool may have been closed while the session was being reset.
+	p.mu.Lock()
+	select {
+	case <-p.done:
+		p.mu.Unlock()
+		p.discard(s)
+	default:
+		p.idle <- s
+		p.mu.Unlock()
+	}
+}
+
+// Discard deletes the session, for instance after it errored, allowing a
+// replacement session to be created by a subsequent call to Get. Sessions
+// which were not handed out by the pool, or which have already been
+// returned, are ignored.
+func (p *Pool) Discard(s *Session) {
+	if p.take(s) {
+		p.discard(s)
+	}
+}
+
+// discard deletes a session taken back from a caller, freeing its slot.
+func (p *Pool) discard(s *Session) {
+	s.WithContext(context.Background()).Delete()
+	p.free <- struct{}{}
+}
+
+// lend records the session as handed out by the pool.
+func (p *Pool) lend(s *Session) *Session {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.out[s.ID] = true
+	return s
+}
+
+// take records the session as returned to the pool, reporting whether it
+// had been handed out by the pool.
+func (p *Pool) take(s *Session) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.out[s.ID] {
+		return false
+	}
+	delete(p.out, s.ID)
+	return true
+}
+
+// Close deletes the idle sessions in the pool. Sessions which are in use
+// are deleted when they are returned to the pool.
+func (p *Pool) Close() error {
+	var err error
+	p.once.Do(func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		close(p.done)
+	})
+	for {
+		select {
+		case s := <-p.idle:
+			if e := s.WithContext(context.Background()).Delete(); e != nil && err == nil {
+				err = e
+			}
+			p.free <- struct{}{}
+		default:
+			return err
+		}
+	}
+}
+
+// reset restores the session to the state of a newly created session.
+func reset(s *Session) error {
+
+	wins, err := s.Windows()
+	if err != nil {
+		return err
+	}
+
+	if len(wins) == 0 {
+		return errors.New("webdriver: session has no open windows")
+	}
+
+	for _, w := range wins[1:] {
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+
+	if err := wins[0].Focus(); err != nil {
+		return err
+	}
+
+	if err := s.CookiesClear(); err != nil {
+		return err
+	}
+
+	// Storage is inaccessible from pages without an origin, such as about:blank.
+	if err := s.LocalStorageClear(); err != nil && !unsupported(err) {
+		return err
+	}
+
+	if err := s.SessionStorageClear(); err != nil && !unsupported(err) {
+		return err
+	}
+
+	return s.Load("about:blank")
+
+}
+
+// unsupported reports whether the error indicates that the remote end, or
+// the current page, does not support the command.
+func unsupported(err error) bool {
+	return errors.Is(err, JavascriptError) || errors.Is(err, UnknownCommand) || errors.Is(err, UnsupportedOperation)
+}
@@ -0,0 +1,160 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/abcum/webdriver"
+	"github.com/abcum/webdriver/webdrivertest"
+)
+
+func TestPool(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv := webdrivertest.NewUnstartedServer()
+			srv.Dialect = d
+			srv.Start()
+			defer srv.Close()
+			p, err := webdriver.NewPool(srv.Driver(), 3, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := srv.Sessions(); n != 3 {
+				t.Fatalf("got %d sessions, want 3", n)
+			}
+			var wg sync.WaitGroup
+			for i := 0; i < 12; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					s, err := p.Get(context.Background())
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					s.Load("http://example.com/")
+					c := s.Cookie("token")
+					c.Value = "abc"
+					c.Set()
+					s.LocalStorageSetKey("theme", "dark")
+					if i%4 == 0 {
+						p.Discard(s)
+						return
+					}
+					p.Put(s)
+				}(i)
+			}
+			wg.Wait()
+			if n := srv.Sessions(); n > 3 {
+				t.Errorf("got %d sessions, want at most 3", n)
+			}
+			s, err := p.Get(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if url, _ := s.Url(); url != "about:blank" {
+				t.Errorf("got url %q, want about:blank", url)
+			}
+			if all, _ := s.Cookies(); len(all) != 0 {
+				t.Errorf("got cookies %v after reset", all)
+			}
+			if wins, _ := s.Windows(); len(wins) != 1 {
+				t.Errorf("got %d windows after reset", len(wins))
+			}
+			p.Put(s)
+			if err := p.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if n := srv.Sessions(); n != 0 {
+				t.Errorf("got %d sessions after close, want 0", n)
+			}
+			if _, err := p.Get(context.Background()); err != webdriver.ErrPoolClosed {
+				t.Errorf("got %v, want %v", err, webdriver.ErrPoolClosed)
+			}
+		})
+	}
+}
+
+func TestPoolReturnTwice(t *testing.T) {
+	srv := webdrivertest.NewServer()
+	defer srv.Close()
+	p, err := webdriver.NewPool(srv.Driver(), 1, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := p.Get(context.Background())
+	p.Discard(s)
+	p.Discard(s)
+	p.Put(s)
+	other, _ := srv.Driver().Session(nil, nil)
+	p.Put(other)
+	if _, err := p.Get(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPoolSize(t *testing.T) {
+	srv := webdrivertest.NewServer()
+	defer srv.Close()
+	for _, size := range []int{0, -1} {
+		if _, err := webdriver.NewPool(srv.Driver(), size, nil, nil); err == nil {
+			t.Errorf("got no error for pool size %d", size)
+		}
+	}
+}
+
+func TestPoolCloseDuringPut(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv := webdrivertest.NewUnstartedServer()
+			srv.Dialect = d
+			srv.Start()
+			defer srv.Close()
+			resetting := make(chan struct{})
+			closed := make(chan struct{})
+			// Block the reset of the returned session until the pool is closed.
+			slow := webdriver.WithInterceptor(func(ctx context.Context, cmd *webdriver.Command, next webdriver.Invoker) error {
+				if cmd.Method == "DELETE" && strings.HasSuffix(cmd.Path, "/cookie") {
+					close(resetting)
+					<-closed
+				}
+				return next(ctx, cmd)
+			})
+			p, err := webdriver.NewPool(srv.Driver(slow), 1, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := p.Get(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				<-resetting
+				if err := p.Close(); err != nil {
+					t.Error(err)
+				}
+				close(closed)
+			}()
+			p.Put(s)
+			if n := srv.Sessions(); n != 0 {
+				t.Errorf("got %d sessions after close, want 0", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// Start launches the driver executable, listening on the port of the driver
+// url, or on a free local port, and blocks until the driver reports that it
+// is ready to create new sessions, or until the start timeout elapses.
+func (w *Driver) Start() error {
+
+	w.mu.Lock()
+
+	w.stopped = false
+
+	// Wait for any restart of a supervised driver which is in progress.
+	for w.starting != nil {
+		wait := w.starting
+		w.mu.Unlock()
+		<-wait
+		w.mu.Lock()
+	}
+
+	if w.cmd != nil {
+		w.mu.Unlock()
+		return nil
+	}
+
+	starting := make(chan struct{})
+	w.starting = starting
+	w.mu.Unlock()
+
+	defer w.started(starting)
+
+	return w.launch()
+
+}
+
+// started marks the launch of the driver executable as complete.
+func (w *Driver) started(starting chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.starting == starting {
+		w.starting = nil
+	}
+	close(starting)
+}
+
+// Stop deletes any sessions created using the driver which are still active,
+// interrupts the driver executable, and waits for it to exit. If it does not
+// exit within the grace period, the driver and every process it started are
+// killed. Stop returns an error if the driver did not exit cleanly.
+func (w *Driver) Stop() error {
+
+	w.mu.Lock()
+	w.stopped = true
+	running := w.cmd != nil
+	w.mu.Unlock()
+
+	if !running {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.grace)
+	for _, s := range w.live() {
+		s.WithContext(ctx).Delete()
+	}
+	cancel()
+
+	w.mu.Lock()
+	cmd, done := w.cmd, w.done
+	w.cmd = nil
+	w.mu.Unlock()
+
+	if cmd == nil {
+		return nil
+	}
+
+	forced, err := interrupt(cmd)
+	if err != nil {
+		forced = false
+		kill(cmd)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(w.grace):
+		kill(cmd)
+		<-done
+		return fmt.Errorf("webdriver: %s did not exit within %s and was killed", w.exe, w.grace)
+	}
+
+	w.mu.Lock()
+	exit := w.exit
+	w.mu.Unlock()
+
+	// An exit caused by the interrupt, or by the kill sent in its place, is clean.
+	if exit != nil && !forced && !interrupted(cmd.ProcessState) {
+		return fmt.Errorf("webdriver: %s exited abnormally: %v", w.exe, exit)
+	}
+
+	return nil
+
+}
+
+// URL returns the url of the remote end. If the driver executable was
+// started on a free local port, the url includes the chosen port.
+func (w *Driver) URL() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.url
+}
+
+// Output returns the most recent lines written by the driver executable
+// to its standard output and standard error streams.
+func (w *Driver) Output() []string {
+	w.mu.Lock()
+	out := w.out
+	w.mu.Unlock()
+	if out == nil {
+		return nil
+	}
+	return out.Lines()
+}
+
+// ProcessState returns the exit status of the driver executable, once it has
+// exited, or nil if the driver has not been started or is still running.
+func (w *Driver) ProcessState() *os.ProcessState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done == nil {
+		return nil
+	}
+	select {
+	case <-w.done:
+		return w.state
+	default:
+		return nil
+	}
+}
+
+// launch starts the driver executable and waits until it is ready.
+func (w *Driver) launch() error {
+
+	if w.exe == "" {
+		return errors.New("webdriver: no driver executable specified")
+	}
+
+	port, err := w.port()
+	if err != nil {
+		return err
+	}
+
+	ring := newRing(w.lines)
+
+	sinks := append([]io.Writer{ring}, w.sinks...)
+
+	var file *os.File
+
+	if w.file != "" {
+		file, err = os.OpenFile(w.file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, file)
+	}
+
+	out := io.MultiWriter(sinks...)
+
+	cmd := w.command(port)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.WaitDelay = w.grace
+
+	group(cmd)
+
+	if err := cmd.Start(); err != nil {
+		if file != nil {
+			file.Close()
+		}
+		return err
+	}
+
+	done := make(chan struct{})
+
+	w.mu.Lock()
+	w.cmd, w.done, w.out, w.up = cmd, done, ring, false
+	w.mu.Unlock()
+
+	go w.reap(cmd, done, file)
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+
+	if err := w.WaitReady(ctx); err != nil {
+		w.mu.Lock()
+		if w.cmd == cmd {
+			w.cmd = nil
+		}
+		w.mu.Unlock()
+		kill(cmd)
+		<-done
+		if out := ring.String(); out != "" {
+			return fmt.Errorf("webdriver: %s failed to start: %v\n%s", w.exe, err, out)
+		}
+		return fmt.Errorf("webdriver: %s failed to start: %v", w.exe, err)
+	}
+
+	w.mu.Lock()
+	w.up = w.cmd == cmd
+	w.mu.Unlock()
+
+	return nil
+
+}
+
+// reap waits for the driver executable to exit, and restarts it if it
+// exited unexpectedly while supervised.
+func (w *Driver) reap(cmd *exec.Cmd, done chan struct{}, file *os.File) {
+
+	err := cmd.Wait()
+
+	if file != nil {
+		file.Close()
+	}
+
+	w.mu.Lock()
+	w.exit, w.state = err, cmd.ProcessState
+	close(done)
+	crashed := w.cmd == cmd && w.up && !w.stopped
+	var starting chan struct{}
+	if crashed {
+		w.cmd, w.up = nil, false
+		if w.supervise {
+			starting = make(chan struct{})
+			w.starting = starting
+		}
+	}
+	w.mu.Unlock()
+
+	if starting != nil {
+		defer w.started(starting)
+		w.restart(err)
+	}
+
+}
+
+// live returns the sessions created using the driver which are still active.
+func (w *Driver) live() []*Session {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := make([]*Session, 0, len(w.sessions))
+	for _, s := range w.sessions {
+		out = append(out, s)
+	}
+	return out
+}
+
+// port returns the port the driver should listen on, choosing a free local
+// port, and updating the driver url, if the original url has no port. The
+// url path is set to /wd/hub for Selenium 3 server jars, if it is empty.
+func (w *Driver) port() (int, error) {
+
+	u, err := url.Parse(w.base)
+	if err != nil {
+		return 0, err
+	}
+
+	if u.Host == "" {
+		u.Scheme, u.Host = "http", "127.0.0.1"
+	}
+
+	if u.Path == "" && w.legacy() {
+		u.Path = "/wd/hub"
+	}
+
+	port, err := strconv.Atoi(u.Port())
+
+	if err != nil {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return 0, err
+		}
+		port = l.Addr().(*net.TCPAddr).Port
+		l.Close()
+		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(port))
+	}
+
+	w.mu.Lock()
+	w.url = u.String()
+	w.mu.Unlock()
+
+	return port, nil
+
+}
@@ -0,0 +1,208 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abcum/webdriver"
+	"github.com/abcum/webdriver/webdrivertest"
+)
+
+// TestMain runs the test binary as a fake driver executable, serving a
+// webdrivertest server, when it is launched by the process tests.
+func TestMain(m *testing.M) {
+	if os.Getenv("WEBDRIVER_FAKE_DRIVER") == "1" {
+		fake()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fake serves a fake remote end on the port specified using --port, behaving
+// as specified by the FAKE_ environment variables.
+func fake() {
+	var port string
+	for _, arg := range os.Args[1:] {
+		if strings.HasPrefix(arg, "--port=") {
+			port = strings.TrimPrefix(arg, "--port=")
+		}
+	}
+	if file := os.Getenv("FAKE_LAUNCHES"); file != "" {
+		f, _ := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		fmt.Fprintln(f, os.Getpid())
+		f.Close()
+	}
+	fmt.Println("fake driver starting on port", port)
+	if os.Getenv("FAKE_EXIT") != "" {
+		os.Exit(0)
+	}
+	if os.Getenv("FAKE_IGNORE") != "" {
+		signal.Ignore(os.Interrupt)
+	}
+	crash := os.Getenv("FAKE_CRASH")
+	// Only the first launch crashes, and later launches are delayed, if a
+	// marker file is specified.
+	if file := os.Getenv("FAKE_CRASH_ONCE"); file != "" {
+		if _, err := os.Stat(file); err == nil {
+			crash = ""
+			time.Sleep(delay("FAKE_RESTART_DELAY"))
+		} else {
+			ioutil.WriteFile(file, nil, 0644)
+		}
+	}
+	time.Sleep(delay("FAKE_DELAY"))
+	if crash != "" {
+		go func() {
+			time.Sleep(delay("FAKE_CRASH"))
+			os.Exit(3)
+		}()
+	}
+	http.ListenAndServe("127.0.0.1:"+port, webdrivertest.NewUnstartedServer())
+}
+
+func delay(key string) time.Duration {
+	d, _ := time.ParseDuration(os.Getenv(key))
+	return d
+}
+
+// driver returns a driver which launches the test binary as a fake driver
+// executable, configured using the specified environment variables.
+func driver(t *testing.T, addr string, env []string, opts ...webdriver.Option) *webdriver.Driver {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env = append([]string{"WEBDRIVER_FAKE_DRIVER=1"}, env...)
+	opts = append([]webdriver.Option{webdriver.WithEnv(env...), webdriver.WithStartTimeout(10 * time.Second)}, opts...)
+	d := webdriver.NewDriver(addr, exe, opts...)
+	t.Cleanup(func() { d.Stop() })
+	return d
+}
+
+func TestStartStop(t *testing.T) {
+	d := driver(t, "", nil)
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, port, _ := net.SplitHostPort(strings.TrimPrefix(d.URL(), "http://")); port == "" || port == "0" {
+		t.Errorf("got url %q, want a free port", d.URL())
+	}
+	if _, err := d.Session(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out := strings.Join(d.Output(), "\n"); !strings.Contains(out, "fake driver starting") {
+		t.Errorf("got output %q", out)
+	}
+	if err := d.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if d.ProcessState() == nil {
+		t.Error("got no process state after stop")
+	}
+	if _, err := d.Status(); err == nil {
+		t.Error("expected an error from a stopped driver")
+	}
+}
+
+func TestStartPort(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	addr := "http://127.0.0.1:" + strconv.Itoa(port)
+	d := driver(t, addr, nil)
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if d.URL() != addr {
+		t.Errorf("got url %q, want %q", d.URL(), addr)
+	}
+	if _, err := d.Status(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartTimeout(t *testing.T) {
+	d := driver(t, "", []string{"FAKE_DELAY=10s"}, webdriver.WithStartTimeout(300*time.Millisecond))
+	now := time.Now()
+	err := d.Start()
+	if err == nil || !strings.Contains(err.Error(), "failed to start") || !strings.Contains(err.Error(), "fake driver starting") {
+		t.Fatalf("got %v, want a start error including the driver output", err)
+	}
+	if time.Since(now) > 5*time.Second {
+		t.Errorf("start took %s, want it to time out", time.Since(now))
+	}
+	if d.ProcessState() == nil {
+		t.Error("the driver was not killed after failing to start")
+	}
+}
+
+func TestStopForced(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("drivers are always killed on windows")
+	}
+	d := driver(t, "", []string{"FAKE_IGNORE=1"}, webdriver.WithGracePeriod(300*time.Millisecond))
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Stop(); err == nil || !strings.Contains(err.Error(), "was killed") {
+		t.Errorf("got %v, want the driver to be killed", err)
+	}
+	if d.ProcessState() == nil {
+		t.Error("got no process state after stop")
+	}
+}
+
+func TestStartExited(t *testing.T) {
+	d := driver(t, "", []string{"FAKE_EXIT=1"})
+	err := d.Start()
+	if err == nil || strings.Contains(err.Error(), "<nil>") || !strings.Contains(err.Error(), "exit status 0") {
+		t.Errorf("got %v, want the exit status", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "fake driver starting") {
+		t.Errorf("got %v, want the driver output", err)
+	}
+}
+
+func TestOutputLines(t *testing.T) {
+	for _, n := range []int{-1, 0, 1} {
+		d := driver(t, "", nil, webdriver.WithOutputLines(n))
+		if err := d.Start(); err != nil {
+			t.Fatal(err)
+		}
+		want := n
+		if want < 0 {
+			want = 0
+		}
+		if out := d.Output(); len(out) != want {
+			t.Errorf("WithOutputLines(%d): got output %q", n, out)
+		}
+		d.Stop()
+	}
+}
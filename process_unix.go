@@ -0,0 +1,47 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package webdriver
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// group starts the command in a new process group, so that any browsers
+// launched by the driver can be killed along with the driver itself.
+func group(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// interrupt asks the driver process to exit gracefully, reporting whether
+// the process was killed instead.
+func interrupt(cmd *exec.Cmd) (bool, error) {
+	return false, cmd.Process.Signal(os.Interrupt)
+}
+
+// kill kills the driver process and every process within its process group.
+func kill(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// interrupted reports whether the process exited because it was interrupted.
+func interrupted(state *os.ProcessState) bool {
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	return ok && ws.Signaled() && ws.Signal() == syscall.SIGINT
+}
@@ -0,0 +1,54 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package webdriver
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+// group starts the command in a new process group.
+func group(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// interrupt asks the driver process to exit gracefully, reporting whether
+// the process was killed instead. Interrupts can not be sent to processes
+// on windows, so the process is killed immediately.
+func interrupt(cmd *exec.Cmd) (bool, error) {
+	return true, kill(cmd)
+}
+
+// kill kills the driver process, along with every process it started, such
+// as browsers. Windows has no process groups which can be signalled, so the
+// process tree is killed using taskkill, falling back to killing only the
+// driver process if taskkill fails.
+func kill(cmd *exec.Cmd) error {
+	tk := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid))
+	if tk.Run() == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
+
+// interrupted reports whether the process exited because it was interrupted.
+func interrupted(state *os.ProcessState) bool {
+	return false
+}
@@ -0,0 +1,225 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Exchange is a single request and response recorded within a transcript.
+type Exchange struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Request  string `json:"request,omitempty"`
+	Status   int    `json:"status"`
+	Response string `json:"response"`
+}
+
+// Recorder is an http transport which writes every exchange with the
+// remote end to a transcript, one JSON encoded exchange per line. By
+// default, every sensitive value sent to the remote end is redacted
+// from the transcript.
+type Recorder struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	enc    *json.Encoder
+	next   http.RoundTripper
+	redact Redaction
+}
+
+// NewRecorder returns a recorder which writes the transcript to out, sending
+// requests using the next transport, or the default transport if nil.
+func NewRecorder(out io.Writer, next http.RoundTripper) *Recorder {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Recorder{mu: new(sync.Mutex), out: out, enc: json.NewEncoder(out), next: next, redact: RedactAll}
+}
+
+// Redact specifies which sensitive values are hidden from recorded request
+// bodies, returning the recorder. Use RedactNone to record every value.
+func (r *Recorder) Redact(rd Redaction) *Recorder {
+	r.redact = rd
+	return r
+}
+
+// RecordFile returns a recorder which writes the transcript to the specified file.
+func RecordFile(file string, next http.RoundTripper) (*Recorder, error) {
+	out, err := os.Create(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewRecorder(out, next), nil
+}
+
+// RoundTrip sends the request using the next transport, and records the exchange.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	ex := Exchange{Method: req.Method, Path: req.URL.RequestURI()}
+
+	if req.Body != nil {
+		buf, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(buf))
+		ex.Request = r.hide(req, buf)
+	}
+
+	res, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	buf, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	res.Body = ioutil.NopCloser(bytes.NewReader(buf))
+
+	ex.Status = res.StatusCode
+	ex.Response = string(buf)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.enc.Encode(ex); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+
+	return res, nil
+
+}
+
+// hide returns the request body, hiding any values marked as sensitive.
+func (r *Recorder) hide(req *http.Request, buf []byte) string {
+	marked, _ := req.Context().Value(secretKey{}).(Redaction)
+	if marked&r.redact == 0 {
+		return string(buf)
+	}
+	var opt map[string]interface{}
+	if err := json.Unmarshal(buf, &opt); err != nil {
+		return redacted
+	}
+	out, _ := json.Marshal(hide(opt, marked, r.redact))
+	return string(out)
+}
+
+// wrap returns a copy of the recorder, writing to the same transcript, which
+// sends requests using the next transport.
+func (r *Recorder) wrap(next http.RoundTripper) *Recorder {
+	cp := *r
+	cp.next = next
+	return &cp
+}
+
+// Close closes the transcript, if it can be closed.
+func (r *Recorder) Close() error {
+	if c, ok := r.out.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Replayer is an http transport which serves the exchanges from a recorded
+// transcript, in order, without contacting any remote end.
+type Replayer struct {
+	mu  sync.Mutex
+	pos int
+	all []Exchange
+}
+
+// NewReplayer returns a replayer which serves the transcript read from in.
+func NewReplayer(in io.Reader) (*Replayer, error) {
+	r := &Replayer{}
+	dec := json.NewDecoder(in)
+	for {
+		var ex Exchange
+		err := dec.Decode(&ex)
+		if err == io.EOF {
+			return r, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		r.all = append(r.all, ex)
+	}
+}
+
+// ReplayFile returns a replayer which serves the transcript read from the specified file.
+func ReplayFile(file string) (*Replayer, error) {
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	return NewReplayer(in)
+}
+
+// RoundTrip serves the next recorded exchange, returning an error if the
+// request does not match the method and path of the recorded request.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pos >= len(r.all) {
+		return nil, fmt.Errorf("replay: unexpected request %s %s: transcript exhausted", req.Method, req.URL.RequestURI())
+	}
+
+	ex := r.all[r.pos]
+
+	if ex.Method != req.Method || ex.Path != req.URL.RequestURI() {
+		return nil, fmt.Errorf("replay: unexpected request %s %s: expected %s %s", req.Method, req.URL.RequestURI(), ex.Method, ex.Path)
+	}
+
+	r.pos++
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", ex.Status, http.StatusText(ex.Status)),
+		StatusCode:    ex.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json;charset=utf-8"}},
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(ex.Response))),
+		ContentLength: int64(len(ex.Response)),
+		Request:       req,
+	}, nil
+
+}
+
+// Remaining returns the number of recorded exchanges which have not been served.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.all) - r.pos
+}
@@ -0,0 +1,134 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abcum/webdriver"
+	"github.com/abcum/webdriver/webdrivertest"
+)
+
+// script runs a sequence of commands, returning their results.
+func script(t *testing.T, d *webdriver.Driver) []string {
+	t.Helper()
+	s, err := d.Session(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Load("http://example.com/login"); err != nil {
+		t.Fatal(err)
+	}
+	title, err := s.Title()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := s.Element(webdriver.FindByName, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Value("hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	text, err := s.Element(webdriver.FindByTagName, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	link, err := text.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	return []string{s.ID, title, e.ID, link}
+}
+
+// record records the script against a server speaking the dialect, into
+// a transcript file, stopping the server afterwards.
+func record(t *testing.T, d webdriver.Dialect) (string, []string) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "transcript.jsonl")
+	srv := webdrivertest.NewUnstartedServer()
+	srv.Dialect = d
+	srv.Start()
+	defer srv.Close()
+	srv.AddPage("http://example.com/login", page())
+	rec, err := webdriver.RecordFile(file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Close()
+	return file, script(t, srv.Driver(webdriver.WithTransport(rec)))
+}
+
+func TestRecordReplay(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			file, want := record(t, d)
+			buf, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Contains(plain(string(buf)), "hunter2") {
+				t.Error("transcript contains a redacted value")
+			}
+			if !strings.Contains(string(buf), "[REDACTED]") {
+				t.Error("transcript contains no redacted values")
+			}
+			rp, err := webdriver.ReplayFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := script(t, webdriver.NewDriver("http://127.0.0.1:0", "", webdriver.WithTransport(rp)))
+			if strings.Join(got, "|") != strings.Join(want, "|") {
+				t.Errorf("replayed %q, recorded %q", got, want)
+			}
+			if n := rp.Remaining(); n != 0 {
+				t.Errorf("got %d exchanges remaining, want 0", n)
+			}
+		})
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	file, _ := record(t, webdriver.W3C)
+	rp, err := webdriver.ReplayFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := webdriver.NewDriver("http://127.0.0.1:0", "", webdriver.WithTransport(rp))
+	// The transcript begins by creating a session, not by requesting the status.
+	if _, err := d.Status(); err == nil || !strings.Contains(err.Error(), "unexpected request GET /status") {
+		t.Errorf("got %v, want an unexpected request error", err)
+	}
+	// A mismatched request is not consumed, so the session can still be created.
+	s, err := d.Session(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Title(); err == nil || !strings.Contains(err.Error(), "expected POST") {
+		t.Errorf("got %v, want an unexpected request error", err)
+	}
+	rp, _ = webdriver.ReplayFile(file)
+	d = webdriver.NewDriver("http://127.0.0.1:0", "", webdriver.WithTransport(rp))
+	script(t, d)
+	if _, err := d.Status(); err == nil || !strings.Contains(err.Error(), "transcript exhausted") {
+		t.Errorf("got %v, want a transcript exhausted error", err)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// Retryable specifies classes of error which can be retried.
+type Retryable int
+
+const (
+	// RetryConnection retries commands when the connection is refused or reset.
+	RetryConnection Retryable = 1 << iota
+	// RetryServer retries commands when the remote end is overloaded or unavailable.
+	RetryServer
+	// RetryStale retries find and other idempotent commands which fail with a
+	// stale element reference, for remote ends which report elements as stale
+	// while the page is changing. It is not included in RetryAll, as commands
+	// on an element which was replaced can never succeed; use a Locator to
+	// find the element again instead.
+	RetryStale
+	// RetryAll retries every class of transient connection and server error.
+	RetryAll = RetryConnection | RetryServer
+)
+
+// RetryPolicy specifies how commands which fail with transient errors are retried.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts, including the first.
+	Attempts int
+	// Backoff is the delay before the first retry, which doubles with each attempt.
+	Backoff time.Duration
+	// MaxBackoff is the maximum delay between attempts, if non-zero.
+	MaxBackoff time.Duration
+	// Retry specifies which classes of error are retried.
+	Retry Retryable
+	// Unsafe enables retrying POST commands which are not idempotent, when
+	// the remote end may have received them. Commands whose connection was
+	// refused are always retried, as they never reached the remote end.
+	Unsafe bool
+}
+
+// WithRetry specifies the policy used to retry commands which fail with transient errors.
+func WithRetry(p RetryPolicy) Option {
+	return func(w *Driver) {
+		w.retry = p
+	}
+}
+
+// retries reports whether the failed command should be attempted again.
+func (p *RetryPolicy) retries(cmd *Command, err error, attempt int) bool {
+	if attempt >= p.Attempts {
+		return false
+	}
+	if p.Retry&RetryConnection != 0 && refused(err) {
+		return true
+	}
+	if !p.Unsafe && !idempotent(cmd) {
+		return false
+	}
+	var e *Error
+	switch {
+	case p.Retry&RetryStale != 0 && errors.Is(err, StaleElementReference):
+		return idempotent(cmd)
+	case p.Retry&RetryConnection != 0 && dropped(err):
+		return true
+	case p.Retry&RetryServer != 0 && errors.As(err, &e) && e.HTTPStatus >= 502 && e.HTTPStatus <= 504:
+		return true
+	}
+	return false
+}
+
+// wait sleeps before the specified attempt, returning early if the context is done.
+func (p *RetryPolicy) wait(ctx context.Context, attempt int) error {
+	t := time.NewTimer(p.backoff(attempt))
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// backoff returns the delay before the specified attempt, doubling the
+// initial delay for each previous retry, up to the maximum delay.
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d > 0; i++ {
+		if p.MaxBackoff > 0 && d >= p.MaxBackoff {
+			break
+		}
+		if d > math.MaxInt64/2 {
+			d = math.MaxInt64
+			break
+		}
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// idempotent reports whether the command can be safely sent more than once.
+func idempotent(cmd *Command) bool {
+	if cmd.Method != "POST" {
+		return true
+	}
+	for _, suffix := range []string{
+		"/element",
+		"/elements",
+		"/element/active",
+		"/timeouts",
+		"/timeouts/async_script",
+		"/timeouts/implicit_wait",
+		"/window/rect",
+		"/size",
+	} {
+		if strings.HasSuffix(cmd.Path, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	for _, c := range []struct {
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{RetryPolicy{Backoff: time.Second}, 1, time.Second},
+		{RetryPolicy{Backoff: time.Second}, 3, 4 * time.Second},
+		{RetryPolicy{Backoff: time.Second, MaxBackoff: 3 * time.Second}, 3, 3 * time.Second},
+		{RetryPolicy{Backoff: time.Second, MaxBackoff: 3 * time.Second}, 1000, 3 * time.Second},
+		{RetryPolicy{Backoff: time.Second}, 64, math.MaxInt64},
+		{RetryPolicy{Backoff: time.Second}, 1000, math.MaxInt64},
+		{RetryPolicy{}, 1000, 0},
+	} {
+		if got := c.policy.backoff(c.attempt); got != c.want {
+			t.Errorf("backoff(%d) with %+v = %v, want %v", c.attempt, c.policy, got, c.want)
+		}
+	}
+}
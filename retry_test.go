@@ -0,0 +1,131 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/abcum/webdriver"
+	"github.com/abcum/webdriver/webdrivertest"
+)
+
+// counter returns an interceptor which counts the attempts of each command.
+func counter(out map[string]int) webdriver.Option {
+	return webdriver.WithInterceptor(func(ctx context.Context, cmd *webdriver.Command, next webdriver.Invoker) error {
+		out[cmd.Method+" "+cmd.Path]++
+		return next(ctx, cmd)
+	})
+}
+
+func TestRetryConnectionRefused(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := "http://" + l.Addr().String()
+	l.Close()
+	calls := make(map[string]int)
+	policy := webdriver.RetryPolicy{Attempts: 3, Backoff: time.Millisecond, Retry: webdriver.RetryAll}
+	d := webdriver.NewDriver(addr, "", webdriver.WithRetry(policy), counter(calls))
+	if _, err := d.Session(nil, nil); err == nil {
+		t.Fatal("expected an error from a closed port")
+	}
+	if _, err := d.Status(); err == nil {
+		t.Fatal("expected an error from a closed port")
+	}
+	if calls["POST /session"] != 3 || calls["GET /status"] != 3 {
+		t.Errorf("got attempts %v, want 3 of each", calls)
+	}
+	calls = make(map[string]int)
+	policy.Retry = webdriver.RetryServer
+	d = webdriver.NewDriver(addr, "", webdriver.WithRetry(policy), counter(calls))
+	if _, err := d.Status(); err == nil {
+		t.Fatal("expected an error from a closed port")
+	}
+	if calls["GET /status"] != 1 {
+		t.Errorf("got attempts %v, want 1", calls)
+	}
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			calls := make(map[string]int)
+			policy := webdriver.RetryPolicy{Attempts: 3, Backoff: time.Millisecond, Retry: webdriver.RetryServer}
+			srv, s := serve(t, d, webdriver.WithRetry(policy), counter(calls))
+			srv.Inject(webdrivertest.Fault{Path: "/title", Status: 503, Times: 2})
+			if _, err := s.Title(); err != nil {
+				t.Errorf("got %v, want success after retries", err)
+			}
+			srv.Inject(webdrivertest.Fault{Path: "/click", Status: 503, Times: 1})
+			e, err := s.Element(webdriver.FindByTagName, "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Click(); err == nil {
+				t.Errorf("got success, want the click not to be retried")
+			}
+			title := "GET /session/" + s.ID + "/title"
+			click := "POST /session/" + s.ID + "/element/" + e.ID + "/click"
+			if calls[title] != 3 || calls[click] != 1 {
+				t.Errorf("got attempts %v", calls)
+			}
+		})
+	}
+}
+
+func TestRetryStale(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			calls := make(map[string]int)
+			policy := webdriver.RetryPolicy{Attempts: 3, Backoff: time.Millisecond, Retry: webdriver.RetryStale, Unsafe: true}
+			srv, s := serve(t, d, webdriver.WithRetry(policy), counter(calls))
+			e, err := s.Element(webdriver.FindByTagName, "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			srv.Inject(webdrivertest.Fault{Path: "/text", Code: webdriver.StaleElementReference, Times: 2})
+			if _, err := e.Text(); err != nil {
+				t.Errorf("got %v, want success after retries", err)
+			}
+			srv.Inject(webdrivertest.Fault{Path: "/click", Code: webdriver.StaleElementReference, Times: 1})
+			if err := e.Click(); !errors.Is(err, webdriver.StaleElementReference) {
+				t.Errorf("got %v, want the click not to be retried", err)
+			}
+			text := "GET /session/" + s.ID + "/element/" + e.ID + "/text"
+			click := "POST /session/" + s.ID + "/element/" + e.ID + "/click"
+			if calls[text] != 3 || calls[click] != 1 {
+				t.Errorf("got attempts %v", calls)
+			}
+		})
+	}
+}
+
+func TestRetryAllExcludesStale(t *testing.T) {
+	calls := make(map[string]int)
+	policy := webdriver.RetryPolicy{Attempts: 3, Backoff: time.Millisecond, Retry: webdriver.RetryAll}
+	srv, s := serve(t, webdriver.W3C, webdriver.WithRetry(policy), counter(calls))
+	srv.Inject(webdrivertest.Fault{Path: "/title", Code: webdriver.StaleElementReference, Times: 1})
+	if _, err := s.Title(); !errors.Is(err, webdriver.StaleElementReference) {
+		t.Errorf("got %v, want %v", err, webdriver.StaleElementReference)
+	}
+	if n := calls["GET /session/"+s.ID+"/title"]; n != 1 {
+		t.Errorf("got %d attempts, want 1", n)
+	}
+}
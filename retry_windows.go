@@ -0,0 +1,40 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package webdriver
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Winsock reports connection errors using its own error numbers, which are
+// not matched by the errors defined by the syscall package.
+const (
+	wsaeconnreset   syscall.Errno = 10054
+	wsaeconnrefused syscall.Errno = 10061
+)
+
+// refused reports whether the connection to the remote end was refused.
+func refused(err error) bool {
+	return errors.Is(err, wsaeconnrefused)
+}
+
+// dropped reports whether the connection to the remote end was reset.
+func dropped(err error) bool {
+	return errors.Is(err, wsaeconnreset)
+}
@@ -16,16 +16,44 @@ package webdriver
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"io"
+	"math"
 )
 
 // Session represents a web page session.
 type Session struct {
-	ID string                 `json:"id"`
-	CB map[string]interface{} `json:"capabilities"`
-	wd *Driver
+	ID      string                 `json:"id"`
+	CB      map[string]interface{} `json:"capabilities"`
+	wd      *Driver
+	ctx     context.Context
+	dialect Dialect
+}
+
+// Context returns the context bound to the session. The returned context
+// is always non-nil; it defaults to the background context.
+func (s *Session) Context() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+	return context.Background()
+}
+
+// WithContext returns a shallow copy of the session bound to the specified
+// context. Every command sent through the returned session, and through any
+// elements, windows or cookies obtained from it, uses the context for
+// cancellation and deadlines. The provided context must be non-nil.
+func (s *Session) WithContext(ctx context.Context) *Session {
+	if ctx == nil {
+		panic("nil context")
+	}
+	s2 := new(Session)
+	*s2 = *s
+	s2.ctx = ctx
+	return s2
 }
 
 // FindStrategy specifies which strategy to use when searching for elements.
@@ -50,14 +78,85 @@ const (
 	XPath = "xpath"
 )
 
+// Dialect returns the protocol dialect negotiated with the remote end.
+func (s *Session) Dialect() Dialect {
+	return s.dialect
+}
+
+func (s *Session) w3c() bool {
+	return s.dialect == W3C
+}
+
+// actions performs a sequence of W3C pointer actions using the specified pointer type.
+func (s *Session) actions(kind string, steps ...map[string]interface{}) error {
+	opt := map[string]interface{}{
+		"actions": []interface{}{
+			map[string]interface{}{
+				"type":       "pointer",
+				"id":         kind,
+				"parameters": map[string]interface{}{"pointerType": kind},
+				"actions":    steps,
+			},
+		},
+	}
+	_, _, err := s.wd.post(s.Context(), "/session/%s/actions", opt, s.ID)
+	return err
+}
+
+// script executes a synchronous script, decoding the result into out.
+func (s *Session) script(out interface{}, script string, args ...interface{}) error {
+	res, err := s.ExecuteSync(script, args)
+	if err != nil || out == nil {
+		return err
+	}
+	return json.Unmarshal(res, out)
+}
+
+// locator builds the search payload, translating strategies unsupported by W3C into CSS selectors.
+func (s *Session) locator(using FindStrategy, value string) map[string]interface{} {
+	if s.w3c() {
+		switch using {
+		case FindById:
+			using, value = FindByCss, "#"+css(value)
+		case FindByName:
+			using, value = FindByCss, "*[name="+quote(value)+"]"
+		case FindByClass:
+			using, value = FindByCss, "."+css(value)
+		}
+	}
+	return map[string]interface{}{"using": using, "value": value}
+}
+
 // Window gets the current active window.
 func (s *Session) Window() *Window {
 	return &Window{ws: s, ID: "current"}
 }
 
+// Windows returns all of the windows opened within the current session.
+func (s *Session) Windows() ([]*Window, error) {
+	path := "/session/%s/window_handles"
+	if s.w3c() {
+		path = "/session/%s/window/handles"
+	}
+	_, res, err := s.wd.get(s.Context(), path, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	err = json.Unmarshal(res, &ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Window, len(ids))
+	for i, id := range ids {
+		out[i] = &Window{ws: s, ID: id}
+	}
+	return out, nil
+}
+
 // Url gets the url of the current page.
 func (s *Session) Url() (string, error) {
-	_, res, err := s.wd.get("/session/%s/url", s.ID)
+	_, res, err := s.wd.get(s.Context(), "/session/%s/url", s.ID)
 	if err != nil {
 		return "", err
 	}
@@ -68,7 +167,7 @@ func (s *Session) Url() (string, error) {
 
 // Title gets the title of the current page.
 func (s *Session) Title() (string, error) {
-	_, res, err := s.wd.get("/session/%s/title", s.ID)
+	_, res, err := s.wd.get(s.Context(), "/session/%s/title", s.ID)
 	if err != nil {
 		return "", err
 	}
@@ -79,7 +178,7 @@ func (s *Session) Title() (string, error) {
 
 // Source gets the source code of the current page.
 func (s *Session) Source() (string, error) {
-	_, res, err := s.wd.get("/session/%s/source", s.ID)
+	_, res, err := s.wd.get(s.Context(), "/session/%s/source", s.ID)
 	if err != nil {
 		return "", err
 	}
@@ -90,73 +189,102 @@ func (s *Session) Source() (string, error) {
 
 // Delete deletes the current session, freeing resources.
 func (s *Session) Delete() error {
-	_, _, err := s.wd.del("/session/%s", s.ID)
+	_, _, err := s.wd.del(s.Context(), "/session/%s", s.ID)
+	if err == nil || errors.Is(err, InvalidSessionID) {
+		s.wd.untrack(s)
+	}
 	return err
 }
 
 // Timeouts enables specifying custom timeouts for the current session.
 func (s *Session) Timeouts(what string, ms int) error {
 	opt := map[string]interface{}{"type": what, "ms": ms}
-	_, _, err := s.wd.post("/session/%s/timeouts", opt, s.ID)
+	if s.w3c() {
+		if what == "page load" {
+			what = "pageLoad"
+		}
+		opt = map[string]interface{}{what: ms}
+	}
+	_, _, err := s.wd.post(s.Context(), "/session/%s/timeouts", opt, s.ID)
 	return err
 }
 
 // TimeoutsAsyncScript specifies a custom timeout when running asynchronous scripts.
 func (s *Session) TimeoutsAsyncScript(ms int) error {
+	if s.w3c() {
+		return s.Timeouts("script", ms)
+	}
 	opt := map[string]interface{}{"ms": ms}
-	_, _, err := s.wd.post("/session/%s/timeouts/async_script", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/timeouts/async_script", opt, s.ID)
 	return err
 }
 
 // TimeoutsImplicitWait specifies a custom timeout when searching for elements on the page.
 func (s *Session) TimeoutsImplicitWait(ms int) error {
+	if s.w3c() {
+		return s.Timeouts("implicit", ms)
+	}
 	opt := map[string]interface{}{"ms": ms}
-	_, _, err := s.wd.post("/session/%s/timeouts/implicit_wait", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/timeouts/implicit_wait", opt, s.ID)
 	return err
 }
 
 // Load loads a new url in the current page.
 func (s *Session) Load(url string) error {
 	opt := map[string]interface{}{"url": url}
-	_, _, err := s.wd.post("/session/%s/url", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/url", opt, s.ID)
 	return err
 }
 
 // Back causes the browser to traverse one step backwards in the session history.
 func (s *Session) Back() error {
-	_, _, err := s.wd.post("/session/%s/back", nil, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/back", nil, s.ID)
 	return err
 }
 
 // Forward causes the browser to traverse one step forwards in the session history.
 func (s *Session) Forward() error {
-	_, _, err := s.wd.post("/session/%s/forward", nil, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/forward", nil, s.ID)
 	return err
 }
 
 // Refresh causes the browser to reload the curretn page.
 func (s *Session) Refresh() error {
-	_, _, err := s.wd.post("/session/%s/refresh", nil, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/refresh", nil, s.ID)
 	return err
 }
 
 // ExecuteSync executes a JavaScript script synchronously in the current page.
 func (s *Session) ExecuteSync(script string, args []interface{}) ([]byte, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
 	opt := map[string]interface{}{"script": script, "args": args}
-	_, res, err := s.wd.post("/session/%s/execute/sync", opt, s.ID)
+	if s.w3c() {
+		_, res, err := s.wd.post(s.Context(), "/session/%s/execute/sync", opt, s.ID)
+		return res, err
+	}
+	_, res, err := s.wd.post(s.Context(), "/session/%s/execute", opt, s.ID)
 	return res, err
 }
 
 // ExecuteAsync executes a JavaScript script asynchronously in the current page.
 func (s *Session) ExecuteAsync(script string, args []interface{}) ([]byte, error) {
+	if args == nil {
+		args = []interface{}{}
+	}
 	opt := map[string]interface{}{"script": script, "args": args}
-	_, res, err := s.wd.post("/session/%s/execute/async", opt, s.ID)
+	if s.w3c() {
+		_, res, err := s.wd.post(s.Context(), "/session/%s/execute/async", opt, s.ID)
+		return res, err
+	}
+	_, res, err := s.wd.post(s.Context(), "/session/%s/execute_async", opt, s.ID)
 	return res, err
 }
 
 // Screenshot takes a screenshot of the full browser viewport.
 func (s *Session) Screenshot() (io.Reader, error) {
-	_, res, err := s.wd.get("/session/%s/screenshot", s.ID)
+	_, res, err := s.wd.get(s.Context(), "/session/%s/screenshot", s.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +293,13 @@ func (s *Session) Screenshot() (io.Reader, error) {
 
 // Active returns the currently active element within the current page.
 func (s *Session) Active() (*Element, error) {
-	_, res, err := s.wd.post("/session/%s/element/active", nil, s.ID)
+	var res []byte
+	var err error
+	if s.w3c() {
+		_, res, err = s.wd.get(s.Context(), "/session/%s/element/active", s.ID)
+	} else {
+		_, res, err = s.wd.post(s.Context(), "/session/%s/element/active", nil, s.ID)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -177,8 +311,8 @@ func (s *Session) Active() (*Element, error) {
 
 // Element searches for a single element from within the current page.
 func (s *Session) Element(using FindStrategy, value string) (*Element, error) {
-	opt := map[string]interface{}{"using": using, "value": value}
-	_, res, err := s.wd.post("/session/%s/element", opt, s.ID)
+	opt := s.locator(using, value)
+	_, res, err := s.wd.post(s.Context(), "/session/%s/element", opt, s.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -190,8 +324,8 @@ func (s *Session) Element(using FindStrategy, value string) (*Element, error) {
 
 // Elements searches for multiple elements from within the current page.
 func (s *Session) Elements(using FindStrategy, value string) ([]*Element, error) {
-	opt := map[string]interface{}{"using": using, "value": value}
-	_, res, err := s.wd.post("/session/%s/elements", opt, s.ID)
+	opt := s.locator(using, value)
+	_, res, err := s.wd.post(s.Context(), "/session/%s/elements", opt, s.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -214,7 +348,11 @@ func (s *Session) Elements(using FindStrategy, value string) ([]*Element, error)
 
 // AlertText returns the text of the currently displayed dialog window.
 func (s *Session) AlertText() (string, error) {
-	_, res, err := s.wd.get("/session/%s/alert_text", s.ID)
+	path := "/session/%s/alert_text"
+	if s.w3c() {
+		path = "/session/%s/alert/text"
+	}
+	_, res, err := s.wd.get(s.Context(), path, s.ID)
 	if err != nil {
 		return "", err
 	}
@@ -225,117 +363,180 @@ func (s *Session) AlertText() (string, error) {
 
 // RespondAlert sends keystrokes to the currently displayed dialog window.
 func (s *Session) RespondAlert(text string) error {
+	path := "/session/%s/alert_text"
+	if s.w3c() {
+		path = "/session/%s/alert/text"
+	}
 	opt := map[string]interface{}{"text": text}
-	_, _, err := s.wd.post("/session/%s/alert_text", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), path, opt, s.ID)
 	return err
 }
 
 // AcceptAlert accepts the currently displayed alert dialog window.
 func (s *Session) AcceptAlert() error {
-	_, _, err := s.wd.post("/session/%s/accept_alert", nil, s.ID)
+	path := "/session/%s/accept_alert"
+	if s.w3c() {
+		path = "/session/%s/alert/accept"
+	}
+	_, _, err := s.wd.post(s.Context(), path, nil, s.ID)
 	return err
 }
 
 // DismissAlert cancels the currently displayed alert dialog window.
 func (s *Session) DismissAlert() error {
-	_, _, err := s.wd.post("/session/%s/dismiss_alert", nil, s.ID)
+	path := "/session/%s/dismiss_alert"
+	if s.w3c() {
+		path = "/session/%s/alert/dismiss"
+	}
+	_, _, err := s.wd.post(s.Context(), path, nil, s.ID)
 	return err
 }
 
 // Move moves the mouse by an offset from the specified element.
 func (s *Session) Move(e *Element, x, y int) error {
+	if s.w3c() {
+		r, err := e.rect()
+		if err != nil {
+			return err
+		}
+		x, y = x-int(r.Width/2), y-int(r.Height/2)
+		return s.actions("mouse", move(e, x, y, 0))
+	}
 	opt := map[string]interface{}{"element": e.ID, "xoffset": x, "yoffset": y}
-	_, _, err := s.wd.post("/session/%s/moveto", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/moveto", opt, s.ID)
 	return err
 }
 
 // Click clicks the specified mouse button at the current coordinates.
 func (s *Session) Click(button int) error {
+	if s.w3c() {
+		return s.actions("mouse", down(button), up(button))
+	}
 	opt := map[string]interface{}{"button": button}
-	_, _, err := s.wd.post("/session/%s/click", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/click", opt, s.ID)
 	return err
 }
 
 // ButtonDown clicks and holds the specified mouse button at the current coordinates.
 func (s *Session) ButtonDown(button int) error {
+	if s.w3c() {
+		return s.actions("mouse", down(button))
+	}
 	opt := map[string]interface{}{"button": button}
-	_, _, err := s.wd.post("/session/%s/buttondown", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/buttondown", opt, s.ID)
 	return err
 }
 
 // ButtonUp releases the specified mouse button at the current coordinates.
 func (s *Session) ButtonUp(button int) error {
+	if s.w3c() {
+		return s.actions("mouse", up(button))
+	}
 	opt := map[string]interface{}{"button": button}
-	_, _, err := s.wd.post("/session/%s/buttonup", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/buttonup", opt, s.ID)
 	return err
 }
 
 // DoubleClick double clicks the left mouse button at the current coordinates.
 func (s *Session) DoubleClick() error {
-	_, _, err := s.wd.post("/session/%s/doubleclick", nil, s.ID)
+	if s.w3c() {
+		return s.actions("mouse", down(0), up(0), down(0), up(0))
+	}
+	_, _, err := s.wd.post(s.Context(), "/session/%s/doubleclick", nil, s.ID)
 	return err
 }
 
 // TouchClick executes a single tap at the current coordinates.
 func (s *Session) TouchClick(e *Element) error {
+	if s.w3c() {
+		return s.actions("touch", move(e, 0, 0, 0), down(0), up(0))
+	}
 	opt := map[string]interface{}{"element": e.ID}
-	_, _, err := s.wd.post("/session/%s/touch/click", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/touch/click", opt, s.ID)
 	return err
 }
 
 // TouchDown presses a finger down on the page at the current coordinates.
 func (s *Session) TouchDown(x, y int) error {
+	if s.w3c() {
+		return s.actions("touch", move("viewport", x, y, 0), down(0))
+	}
 	opt := map[string]interface{}{"x": x, "y": y}
-	_, _, err := s.wd.post("/session/%s/touch/down", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/touch/down", opt, s.ID)
 	return err
 }
 
 // TouchUp lifts a finger up from the page at the current coordinates.
 func (s *Session) TouchUp(x, y int) error {
+	if s.w3c() {
+		return s.actions("touch", move("viewport", x, y, 0), up(0))
+	}
 	opt := map[string]interface{}{"x": x, "y": y}
-	_, _, err := s.wd.post("/session/%s/touch/up", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/touch/up", opt, s.ID)
 	return err
 }
 
 // TouchMove moves the currently pressed finsed on the page.
 func (s *Session) TouchMove(x, y int) error {
+	if s.w3c() {
+		return s.actions("touch", move("viewport", x, y, 0))
+	}
 	opt := map[string]interface{}{"x": x, "y": y}
-	_, _, err := s.wd.post("/session/%s/touch/move", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/touch/move", opt, s.ID)
 	return err
 }
 
 // TouchScroll scrolls on the page using finger based motion events.
 func (s *Session) TouchScroll(e *Element, x, y int) error {
+	if s.w3c() {
+		return s.actions("touch", move(e, 0, 0, 0), down(0), move("pointer", x, y, 250), up(0))
+	}
 	opt := map[string]interface{}{"element": e.ID, "xoffset": x, "yoffset": y}
-	_, _, err := s.wd.post("/session/%s/touch/scroll", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/touch/scroll", opt, s.ID)
 	return err
 }
 
 // TouchDoubleClick executes a double click on the specified element.
 func (s *Session) TouchDoubleClick(e *Element) error {
+	if s.w3c() {
+		return s.actions("touch", move(e, 0, 0, 0), down(0), up(0), down(0), up(0))
+	}
 	opt := map[string]interface{}{"element": e.ID}
-	_, _, err := s.wd.post("/session/%s/touch/doubleclick", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/touch/doubleclick", opt, s.ID)
 	return err
 }
 
 // TouchLongClick executes a long tap on the specified element.
 func (s *Session) TouchLongClick(e *Element) error {
+	if s.w3c() {
+		return s.actions("touch", move(e, 0, 0, 0), down(0), pause(1000), up(0))
+	}
 	opt := map[string]interface{}{"element": e.ID}
-	_, _, err := s.wd.post("/session/%s/touch/longclick", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/touch/longclick", opt, s.ID)
 	return err
 }
 
 // TouchFlick flicks a finger on the screen starting at the specified element.
 func (s *Session) TouchFlick(e *Element, x, y, speed int) error {
+	if s.w3c() {
+		ms := 100
+		if speed > 0 {
+			ms = int(math.Hypot(float64(x), float64(y)) * 1000 / float64(speed))
+		}
+		return s.actions("touch", move(e, 0, 0, 0), down(0), move("pointer", x, y, ms), up(0))
+	}
 	opt := map[string]interface{}{"element": e.ID, "xoffset": x, "yoffset": y, "speed": speed}
-	_, _, err := s.wd.post("/session/%s/touch/flick", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/touch/flick", opt, s.ID)
 	return err
 }
 
 // TouchFlickAnywhere flicks a finger on the screen starting anywhere.
 func (s *Session) TouchFlickAnywhere(xspeed, yspeed int) error {
+	if s.w3c() {
+		return s.actions("touch", down(0), move("pointer", xspeed/10, yspeed/10, 100), up(0))
+	}
 	opt := map[string]interface{}{"xspeed": xspeed, "yspeed": yspeed}
-	_, _, err := s.wd.post("/session/%s/touch/flick", opt, s.ID)
+	_, _, err := s.wd.post(s.Context(), "/session/%s/touch/flick", opt, s.ID)
 	return err
 }
 
@@ -346,7 +547,7 @@ func (s *Session) Cookie(name string) *Cookie {
 
 // Cookies returns all of the cookies visible to the current page.
 func (s *Session) Cookies() ([]Cookie, error) {
-	_, res, err := s.wd.get("/session/%s/cookie", s.ID)
+	_, res, err := s.wd.get(s.Context(), "/session/%s/cookie", s.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -357,13 +558,18 @@ func (s *Session) Cookies() ([]Cookie, error) {
 
 // CookiesClear removes all cookies visible to the current page.
 func (s *Session) CookiesClear() error {
-	_, _, err := s.wd.del("/session/%s/cookie", s.ID)
+	_, _, err := s.wd.del(s.Context(), "/session/%s/cookie", s.ID)
 	return err
 }
 
 // LocalStorageSize returns the current localStorage content size.
 func (s *Session) LocalStorageSize() (int, error) {
-	_, res, err := s.wd.get("/session/%s/local_storage/size", s.ID)
+	if s.w3c() {
+		var out int
+		err := s.script(&out, "return window[arguments[0]].length", "localStorage")
+		return out, err
+	}
+	_, res, err := s.wd.get(s.Context(), "/session/%s/local_storage/size", s.ID)
 	if err != nil {
 		return -1, err
 	}
@@ -374,13 +580,21 @@ func (s *Session) LocalStorageSize() (int, error) {
 
 // LocalStorageClear clears the localStorage of the current page.
 func (s *Session) LocalStorageClear() error {
-	_, _, err := s.wd.del("/session/%s/local_storage", s.ID)
+	if s.w3c() {
+		return s.script(nil, "window[arguments[0]].clear()", "localStorage")
+	}
+	_, _, err := s.wd.del(s.Context(), "/session/%s/local_storage", s.ID)
 	return err
 }
 
 // LocalStorageKeys returns all of the localStorage keys for the current page.
 func (s *Session) LocalStorageKeys() ([]string, error) {
-	_, res, err := s.wd.get("/session/%s/local_storage", s.ID)
+	if s.w3c() {
+		var out []string
+		err := s.script(&out, "return Object.keys(window[arguments[0]])", "localStorage")
+		return out, err
+	}
+	_, res, err := s.wd.get(s.Context(), "/session/%s/local_storage", s.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -391,7 +605,12 @@ func (s *Session) LocalStorageKeys() ([]string, error) {
 
 // LocalStorageGetKey gets the specified localStorage key on the current page.
 func (s *Session) LocalStorageGetKey(key string) (string, error) {
-	_, res, err := s.wd.get("/session/%s/local_storage/key/%s", s.ID, key)
+	if s.w3c() {
+		var out string
+		err := s.script(&out, "return window[arguments[0]].getItem(arguments[1])", "localStorage", key)
+		return out, err
+	}
+	_, res, err := s.wd.get(s.Context(), "/session/%s/local_storage/key/%s", s.ID, key)
 	if err != nil {
 		return "", err
 	}
@@ -402,20 +621,31 @@ func (s *Session) LocalStorageGetKey(key string) (string, error) {
 
 // LocalStorageDelKey deletes the specified key from localStorage on the current page.
 func (s *Session) LocalStorageDelKey(key string) error {
-	_, _, err := s.wd.del("/session/%s/local_storage/key/%s", s.ID, key)
+	if s.w3c() {
+		return s.script(nil, "window[arguments[0]].removeItem(arguments[1])", "localStorage", key)
+	}
+	_, _, err := s.wd.del(s.Context(), "/session/%s/local_storage/key/%s", s.ID, key)
 	return err
 }
 
 // LocalStorageSetKey sets the specified key in localStorage on the current page.
 func (s *Session) LocalStorageSetKey(key, value string) error {
+	if s.w3c() {
+		return s.WithContext(secret(s.Context(), RedactStorage)).script(nil, "window[arguments[0]].setItem(arguments[1], arguments[2])", "localStorage", key, value)
+	}
 	opt := map[string]interface{}{"key": key, "value": value}
-	_, _, err := s.wd.post("/session/%s/local_storage", opt, s.ID)
+	_, _, err := s.wd.post(secret(s.Context(), RedactStorage), "/session/%s/local_storage", opt, s.ID)
 	return err
 }
 
 // SessionStorageSize returns the current sessionStorage content size.
 func (s *Session) SessionStorageSize() (int, error) {
-	_, res, err := s.wd.get("/session/%s/session_storage/size", s.ID)
+	if s.w3c() {
+		var out int
+		err := s.script(&out, "return window[arguments[0]].length", "sessionStorage")
+		return out, err
+	}
+	_, res, err := s.wd.get(s.Context(), "/session/%s/session_storage/size", s.ID)
 	if err != nil {
 		return -1, err
 	}
@@ -426,13 +656,21 @@ func (s *Session) SessionStorageSize() (int, error) {
 
 // SessionStorageClear clears the sessionStorage of the current page.
 func (s *Session) SessionStorageClear() error {
-	_, _, err := s.wd.del("/session/%s/session_storage", s.ID)
+	if s.w3c() {
+		return s.script(nil, "window[arguments[0]].clear()", "sessionStorage")
+	}
+	_, _, err := s.wd.del(s.Context(), "/session/%s/session_storage", s.ID)
 	return err
 }
 
 // SessionStorageKeys returns all of the sessionStorage keys for the current page.
 func (s *Session) SessionStorageKeys() ([]string, error) {
-	_, res, err := s.wd.get("/session/%s/session_storage", s.ID)
+	if s.w3c() {
+		var out []string
+		err := s.script(&out, "return Object.keys(window[arguments[0]])", "sessionStorage")
+		return out, err
+	}
+	_, res, err := s.wd.get(s.Context(), "/session/%s/session_storage", s.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -443,7 +681,12 @@ func (s *Session) SessionStorageKeys() ([]string, error) {
 
 // SessionStorageGetKey gets the specified sessionStorage key on the current page.
 func (s *Session) SessionStorageGetKey(key string) (string, error) {
-	_, res, err := s.wd.get("/session/%s/session_storage/key/%s", s.ID, key)
+	if s.w3c() {
+		var out string
+		err := s.script(&out, "return window[arguments[0]].getItem(arguments[1])", "sessionStorage", key)
+		return out, err
+	}
+	_, res, err := s.wd.get(s.Context(), "/session/%s/session_storage/key/%s", s.ID, key)
 	if err != nil {
 		return "", err
 	}
@@ -454,20 +697,31 @@ func (s *Session) SessionStorageGetKey(key string) (string, error) {
 
 // SessionStorageDelKey deletes the specified key from sessionStorage on the current page.
 func (s *Session) SessionStorageDelKey(key string) error {
-	_, _, err := s.wd.del("/session/%s/session_storage/key/%s", s.ID, key)
+	if s.w3c() {
+		return s.script(nil, "window[arguments[0]].removeItem(arguments[1])", "sessionStorage", key)
+	}
+	_, _, err := s.wd.del(s.Context(), "/session/%s/session_storage/key/%s", s.ID, key)
 	return err
 }
 
 // SessionStorageSetKey sets the specified key in sessionStorage on the current page.
 func (s *Session) SessionStorageSetKey(key, value string) error {
+	if s.w3c() {
+		return s.WithContext(secret(s.Context(), RedactStorage)).script(nil, "window[arguments[0]].setItem(arguments[1], arguments[2])", "sessionStorage", key, value)
+	}
 	opt := map[string]interface{}{"key": key, "value": value}
-	_, _, err := s.wd.post("/session/%s/session_storage", opt, s.ID)
+	_, _, err := s.wd.post(secret(s.Context(), RedactStorage), "/session/%s/session_storage", opt, s.ID)
 	return err
 }
 
 // ApplicationCacheStatus returns the current application cache status for the current page.
 func (s *Session) ApplicationCacheStatus() (int, error) {
-	_, res, err := s.wd.get("/session/%s/application_cache/status", s.ID)
+	if s.w3c() {
+		var out int
+		err := s.script(&out, "return window.applicationCache ? window.applicationCache.status : 0")
+		return out, err
+	}
+	_, res, err := s.wd.get(s.Context(), "/session/%s/application_cache/status", s.ID)
 	if err != nil {
 		return 0, err
 	}
@@ -475,3 +729,19 @@ func (s *Session) ApplicationCacheStatus() (int, error) {
 	err = json.Unmarshal(res, &out)
 	return out, err
 }
+
+func move(origin interface{}, x, y, ms int) map[string]interface{} {
+	return map[string]interface{}{"type": "pointerMove", "origin": origin, "x": x, "y": y, "duration": ms}
+}
+
+func down(button int) map[string]interface{} {
+	return map[string]interface{}{"type": "pointerDown", "button": button}
+}
+
+func up(button int) map[string]interface{} {
+	return map[string]interface{}{"type": "pointerUp", "button": button}
+}
+
+func pause(ms int) map[string]interface{} {
+	return map[string]interface{}{"type": "pause", "duration": ms}
+}
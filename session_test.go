@@ -0,0 +1,88 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/abcum/webdriver"
+)
+
+func TestStorage(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			_, s := serve(t, d)
+			if err := s.LocalStorageSetKey("theme", "dark"); err != nil {
+				t.Fatal(err)
+			}
+			if v, err := s.LocalStorageGetKey("theme"); err != nil || v != "dark" {
+				t.Errorf("got %q, %v", v, err)
+			}
+			if n, err := s.LocalStorageSize(); err != nil || n != 1 {
+				t.Errorf("got size %d, %v", n, err)
+			}
+			if keys, err := s.LocalStorageKeys(); err != nil || len(keys) != 1 || keys[0] != "theme" {
+				t.Errorf("got keys %v, %v", keys, err)
+			}
+			if err := s.LocalStorageDelKey("theme"); err != nil {
+				t.Fatal(err)
+			}
+			if n, _ := s.LocalStorageSize(); n != 0 {
+				t.Errorf("got size %d after delete", n)
+			}
+			if err := s.SessionStorageSetKey("tab", "1"); err != nil {
+				t.Fatal(err)
+			}
+			if v, err := s.SessionStorageGetKey("tab"); err != nil || v != "1" {
+				t.Errorf("got %q, %v", v, err)
+			}
+			if err := s.SessionStorageClear(); err != nil {
+				t.Fatal(err)
+			}
+			if n, _ := s.SessionStorageSize(); n != 0 {
+				t.Errorf("got size %d after clear", n)
+			}
+		})
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			if _, err := s.AlertText(); !errors.Is(err, webdriver.NoSuchAlert) {
+				t.Errorf("got %v, want %v", err, webdriver.NoSuchAlert)
+			}
+			srv.Alert("Are you sure?")
+			if txt, err := s.AlertText(); err != nil || txt != "Are you sure?" {
+				t.Errorf("got %q, %v", txt, err)
+			}
+			if err := s.AcceptAlert(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.AlertText(); !errors.Is(err, webdriver.NoSuchAlert) {
+				t.Errorf("got %v after accept, want %v", err, webdriver.NoSuchAlert)
+			}
+			srv.Alert("Name?")
+			if err := s.RespondAlert("alice"); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.DismissAlert(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status describes the readiness of the remote end.
+type Status struct {
+	// Ready reports whether the remote end can create new sessions.
+	Ready bool
+	// Message describes the readiness of the remote end.
+	Message string
+	// Build describes the build of the remote end, if reported.
+	Build BuildInfo
+	// OS describes the operating system of the remote end, if reported.
+	OS OSInfo
+}
+
+// BuildInfo describes the build of the remote end.
+type BuildInfo struct {
+	Version  string `json:"version"`
+	Revision string `json:"revision"`
+	Time     string `json:"time"`
+}
+
+// OSInfo describes the operating system of the remote end.
+type OSInfo struct {
+	Name    string `json:"name"`
+	Arch    string `json:"arch"`
+	Version string `json:"version"`
+}
+
+// Status returns the readiness of the remote end.
+func (w *Driver) Status() (*Status, error) {
+	return w.StatusContext(context.Background())
+}
+
+// StatusContext returns the readiness of the remote end using the specified context.
+func (w *Driver) StatusContext(ctx context.Context) (*Status, error) {
+
+	obj, err := w.send(ctx, "GET", "/status", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var val struct {
+		Ready   *bool     `json:"ready"`
+		Message string    `json:"message"`
+		Build   BuildInfo `json:"build"`
+		OS      OSInfo    `json:"os"`
+	}
+
+	if err := json.Unmarshal(obj.Value, &val); err != nil {
+		return nil, err
+	}
+
+	// JSON Wire remote ends only respond when they are ready.
+	out := &Status{Ready: true, Message: val.Message, Build: val.Build, OS: val.OS}
+
+	if val.Ready != nil {
+		out.Ready = *val.Ready
+	}
+
+	return out, nil
+
+}
+
+// Ping returns an error if the remote end is not ready to create new sessions.
+func (w *Driver) Ping(ctx context.Context) error {
+	st, err := w.StatusContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !st.Ready {
+		if st.Message != "" {
+			return errors.New("webdriver: remote end is not ready: " + st.Message)
+		}
+		return errors.New("webdriver: remote end is not ready")
+	}
+	return nil
+}
+
+// WaitReady blocks until the remote end is ready to create new sessions, the
+// driver executable exits, or the context is done, returning the last error
+// reported by the remote end if it did not become ready.
+func (w *Driver) WaitReady(ctx context.Context) error {
+
+	w.mu.Lock()
+	done := w.done
+	w.mu.Unlock()
+
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+
+	for {
+
+		err := w.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-done:
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			// A driver which exits cleanly has no exit error.
+			if w.exit == nil {
+				return fmt.Errorf("process exited: %v", w.state)
+			}
+			return fmt.Errorf("process exited: %v", w.exit)
+		case <-ctx.Done():
+			return fmt.Errorf("%v: %v", ctx.Err(), err)
+		case <-tick.C:
+		}
+
+	}
+
+}
@@ -0,0 +1,115 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EventType identifies a change in the state of a supervised driver executable.
+type EventType int
+
+const (
+	// DriverExited is sent when the driver executable exits unexpectedly.
+	DriverExited EventType = iota
+	// DriverRestarted is sent when the driver executable has been restarted,
+	// and is ready to create new sessions.
+	DriverRestarted
+	// DriverFailed is sent when the driver executable could not be restarted.
+	DriverFailed
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case DriverExited:
+		return "exited"
+	case DriverRestarted:
+		return "restarted"
+	case DriverFailed:
+		return "failed"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// Event describes a change in the state of a supervised driver executable.
+type Event struct {
+	// Type identifies the change.
+	Type EventType
+	// URL is the url of the remote end. When the driver executable has
+	// been restarted on a new port, this is the new url.
+	URL string
+	// Sessions contains the IDs of the sessions lost when the driver
+	// executable exited.
+	Sessions []string
+	// Err is the exit error of the driver executable, or the reason it
+	// could not be restarted.
+	Err error
+}
+
+// restart relaunches a supervised driver executable which exited unexpectedly.
+func (w *Driver) restart(cause error) {
+
+	w.mu.Lock()
+	lost := make([]string, 0, len(w.sessions))
+	for id := range w.sessions {
+		lost = append(lost, id)
+	}
+	w.sessions = nil
+	addr := w.url
+	w.mu.Unlock()
+
+	sort.Strings(lost)
+
+	w.emit(Event{Type: DriverExited, URL: addr, Sessions: lost, Err: cause})
+
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return
+	}
+	if w.limit > 0 && w.restarts >= w.limit {
+		w.mu.Unlock()
+		w.emit(Event{Type: DriverFailed, URL: addr, Err: fmt.Errorf("webdriver: %s exceeded its limit of %d restarts", w.exe, w.limit)})
+		return
+	}
+	w.restarts++
+	w.mu.Unlock()
+
+	if err := w.launch(); err != nil {
+		w.emit(Event{Type: DriverFailed, URL: addr, Err: err})
+		return
+	}
+
+	w.mu.Lock()
+	stopped := w.stopped
+	w.mu.Unlock()
+
+	if stopped {
+		w.Stop()
+		return
+	}
+
+	w.emit(Event{Type: DriverRestarted, URL: w.URL()})
+
+}
+
+// emit sends the event to the supervisor callback, if any.
+func (w *Driver) emit(e Event) {
+	if w.notify != nil {
+		w.notify(e)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abcum/webdriver"
+)
+
+// events returns a supervisor which sends every event to the returned channel.
+func events(limit int) (webdriver.Option, chan webdriver.Event) {
+	ch := make(chan webdriver.Event, 16)
+	return webdriver.WithSupervisor(limit, func(e webdriver.Event) { ch <- e }), ch
+}
+
+// next waits for the next supervisor event, checking its type.
+func next(t *testing.T, ch chan webdriver.Event, want webdriver.EventType) webdriver.Event {
+	t.Helper()
+	select {
+	case e := <-ch:
+		if e.Type != want {
+			t.Fatalf("got %s event (%v), want %s", e.Type, e.Err, want)
+		}
+		return e
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for %s event", want)
+	}
+	return webdriver.Event{}
+}
+
+// launches returns the number of times the fake driver was launched.
+func launches(t *testing.T, file string) int {
+	t.Helper()
+	buf, _ := ioutil.ReadFile(file)
+	return strings.Count(string(buf), "\n")
+}
+
+func TestSupervisorRestart(t *testing.T) {
+	dir := t.TempDir()
+	opt, ch := events(0)
+	env := []string{"FAKE_CRASH=500ms", "FAKE_CRASH_ONCE=" + filepath.Join(dir, "crashed")}
+	d := driver(t, "", env, opt)
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	s, err := d.Session(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := next(t, ch, webdriver.DriverExited)
+	if len(e.Sessions) != 1 || e.Sessions[0] != s.ID || e.Err == nil {
+		t.Errorf("got exited event %+v", e)
+	}
+	e = next(t, ch, webdriver.DriverRestarted)
+	if e.URL != d.URL() {
+		t.Errorf("got restarted url %q, want %q", e.URL, d.URL())
+	}
+	if _, err := d.Session(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSupervisorLimit(t *testing.T) {
+	opt, ch := events(1)
+	d := driver(t, "", []string{"FAKE_CRASH=300ms"}, opt)
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	next(t, ch, webdriver.DriverExited)
+	next(t, ch, webdriver.DriverRestarted)
+	next(t, ch, webdriver.DriverExited)
+	if e := next(t, ch, webdriver.DriverFailed); e.Err == nil || !strings.Contains(e.Err.Error(), "limit of 1 restarts") {
+		t.Errorf("got failed event %+v", e)
+	}
+}
+
+func TestStartDuringRestart(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "launches")
+	exited := make(chan struct{})
+	proceed := make(chan struct{})
+	restarted := make(chan struct{})
+	// Hold the restart until Start has been called.
+	opt := webdriver.WithSupervisor(0, func(e webdriver.Event) {
+		switch e.Type {
+		case webdriver.DriverExited:
+			close(exited)
+			<-proceed
+		default:
+			close(restarted)
+		}
+	})
+	env := []string{"FAKE_CRASH=300ms", "FAKE_CRASH_ONCE=" + filepath.Join(dir, "crashed"), "FAKE_LAUNCHES=" + log}
+	d := driver(t, "", env, opt)
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	<-exited
+	time.AfterFunc(200*time.Millisecond, func() { close(proceed) })
+	// Start waits for the restart in progress, rather than launching again.
+	if err := d.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Session(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	<-restarted
+	if n := launches(t, log); n != 2 {
+		t.Errorf("driver launched %d times, want 2", n)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Timeouts specifies the timeouts of a session. A nil timeout is left
+// unchanged when setting timeouts, and is unset or unlimited when read.
+type Timeouts struct {
+	// Script specifies how long scripts may run before they are interrupted.
+	Script *time.Duration
+	// PageLoad specifies how long to wait for a page to load.
+	PageLoad *time.Duration
+	// Implicit specifies how long to wait when searching for elements.
+	Implicit *time.Duration
+}
+
+// Duration returns a pointer to the duration, for use within Timeouts.
+func Duration(d time.Duration) *time.Duration {
+	return &d
+}
+
+// millis contains timeouts in milliseconds, as encoded by the remote end.
+type millis struct {
+	Script   *int64 `json:"script"`
+	PageLoad *int64 `json:"pageLoad"`
+	Implicit *int64 `json:"implicit"`
+}
+
+// timeouts converts the timeouts into durations.
+func (m millis) timeouts() Timeouts {
+	conv := func(ms *int64) *time.Duration {
+		if ms == nil {
+			return nil
+		}
+		return Duration(time.Duration(*ms) * time.Millisecond)
+	}
+	return Timeouts{Script: conv(m.Script), PageLoad: conv(m.PageLoad), Implicit: conv(m.Implicit)}
+}
+
+// SetTimeouts sets the script, page load and implicit wait timeouts for the
+// current session, leaving any nil timeouts unchanged. JSON Wire remote ends
+// are sent one command for each timeout.
+func (s *Session) SetTimeouts(t Timeouts) error {
+	kinds := []struct {
+		w3c, old string
+		val      *time.Duration
+	}{
+		{"script", "script", t.Script},
+		{"pageLoad", "page load", t.PageLoad},
+		{"implicit", "implicit", t.Implicit},
+	}
+	if s.w3c() {
+		opt := make(map[string]interface{})
+		for _, k := range kinds {
+			if k.val != nil {
+				opt[k.w3c] = k.val.Milliseconds()
+			}
+		}
+		if len(opt) == 0 {
+			return nil
+		}
+		_, _, err := s.wd.post(s.Context(), "/session/%s/timeouts", opt, s.ID)
+		return err
+	}
+	for _, k := range kinds {
+		if k.val != nil {
+			if err := s.Timeouts(k.old, int(k.val.Milliseconds())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetTimeouts returns the script, page load and implicit wait timeouts for
+// the current session. Most JSON Wire remote ends do not support this.
+func (s *Session) GetTimeouts() (Timeouts, error) {
+	_, res, err := s.wd.get(s.Context(), "/session/%s/timeouts", s.ID)
+	if err != nil {
+		return Timeouts{}, err
+	}
+	var out millis
+	err = json.Unmarshal(res, &out)
+	return out.timeouts(), err
+}
@@ -0,0 +1,53 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abcum/webdriver"
+)
+
+func TestTimeouts(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			_, s := serve(t, d)
+			if err := s.SetTimeouts(webdriver.Timeouts{Script: webdriver.Duration(2 * time.Second), Implicit: webdriver.Duration(time.Second)}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.SetTimeouts(webdriver.Timeouts{Implicit: webdriver.Duration(0)}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := s.GetTimeouts()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]struct {
+				got  *time.Duration
+				want time.Duration
+			}{
+				"script":   {got.Script, 2 * time.Second},
+				"pageLoad": {got.PageLoad, 300 * time.Second},
+				"implicit": {got.Implicit, 0},
+			}
+			for k, v := range want {
+				if v.got == nil || *v.got != v.want {
+					t.Errorf("%s timeout = %v, want %v", k, v.got, v.want)
+				}
+			}
+		})
+	}
+}
@@ -15,11 +15,11 @@
 package webdriver
 
 import (
+	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"net"
-	"time"
+	"strings"
+	"sync"
 )
 
 type pos struct {
@@ -38,43 +38,100 @@ type size struct {
 	Height int `json:"height" console:"height"`
 }
 
+type rect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
 type response struct {
 	SessionId json.RawMessage `json:"sessionId"`
-	Status    int             `json:"status"`
+	Status    *int            `json:"status"`
 	Value     json.RawMessage `json:"value"`
 }
 
-func oops(c int, obj *response) error {
-	switch c {
-	case 400:
-		return errors.New("400: Missing Command Parameters")
-	case 404:
-		return errors.New("404: Unknown command/Resource Not Found")
-	case 405:
-		return errors.New("405: Invalid Command Method")
-	case 500:
-		return errors.New("500: Failed Command")
-	case 501:
-		return errors.New("501: Unimplemented Command")
-	default:
-		return errors.New("Unknown error")
+// status returns the JSON Wire status code, which is absent from W3C responses.
+func (r *response) status() int {
+	if r.Status == nil {
+		return 0
+	}
+	return *r.Status
+}
+
+// dialect reports the protocol dialect which the response was encoded in.
+func (r *response) dialect() Dialect {
+	if r.Status == nil {
+		return W3C
+	}
+	return JSONWire
+}
+
+func (r *response) session() string {
+	return string(bytes.Trim(r.SessionId, "{}\""))
+}
+
+func css(value string) string {
+	var out strings.Builder
+	for i, r := range value {
+		switch {
+		case r >= '0' && r <= '9' && i == 0:
+			fmt.Fprintf(&out, "\\%x ", r)
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-', r == '_', r > 127:
+			out.WriteRune(r)
+		default:
+			out.WriteRune('\\')
+			out.WriteRune(r)
+		}
 	}
+	return out.String()
 }
 
-func wait(port int, timeout time.Duration) error {
-	address := fmt.Sprintf("127.0.0.1:%d", port)
-	now := time.Now()
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// ring is a writer which keeps the most recent lines written to it.
+type ring struct {
+	mu   sync.Mutex
+	max  int
+	all  []string
+	part []byte
+}
+
+func newRing(max int) *ring {
+	return &ring{max: max}
+}
+
+func (r *ring) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.part = append(r.part, p...)
 	for {
-		if conn, err := net.Dial("tcp", address); err == nil {
-			if err = conn.Close(); err != nil {
-				return err
-			}
+		i := bytes.IndexByte(r.part, '\n')
+		if i < 0 {
 			break
 		}
-		if time.Since(now) > timeout {
-			return errors.New("start failed: timeout expired")
+		r.all = append(r.all, strings.TrimRight(string(r.part[:i]), "\r"))
+		r.part = r.part[i+1:]
+		if len(r.all) > r.max {
+			r.all = r.all[len(r.all)-r.max:]
 		}
-		time.Sleep(1 * time.Second)
 	}
-	return nil
+	return len(p), nil
+}
+
+// Lines returns the most recent lines, including any unterminated line.
+func (r *ring) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := append([]string{}, r.all...)
+	if len(r.part) > 0 {
+		out = append(out, string(r.part))
+	}
+	return out
+}
+
+func (r *ring) String() string {
+	return strings.Join(r.Lines(), "\n")
 }
@@ -0,0 +1,97 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Condition reports whether the session has reached an expected state.
+type Condition func(s *Session) (bool, error)
+
+// WaitOptions specifies how a condition is polled.
+type WaitOptions struct {
+	// Timeout specifies how long to wait for the condition. If zero, only
+	// the context limits how long to wait.
+	Timeout time.Duration
+	// Interval specifies how often the condition is polled. If zero, the
+	// condition is polled every 500 milliseconds.
+	Interval time.Duration
+	// Ignore specifies errors, matched using errors.Is, which are treated
+	// as the condition not being met, rather than ending the wait.
+	Ignore []error
+}
+
+// WaitUntil polls the condition until it is met, it returns an error which
+// is not ignored, or the timeout elapses or the context is done. On timeout
+// the last ignored error returned by the condition is returned, wrapped so
+// that it can be inspected using errors.Is and errors.As.
+func (s *Session) WaitUntil(ctx context.Context, cond Condition, opts WaitOptions) error {
+
+	if opts.Interval <= 0 {
+		opts.Interval = 500 * time.Millisecond
+	}
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	tick := time.NewTicker(opts.Interval)
+	defer tick.Stop()
+
+	var last error
+
+	for {
+
+		ok, err := cond(s.WithContext(ctx))
+
+		switch {
+		case err == nil && ok:
+			return nil
+		case err != nil && ctx.Err() != nil:
+			// The command was interrupted by the end of the wait.
+		case err != nil && !ignored(err, opts.Ignore):
+			return err
+		case err != nil:
+			last = err
+		}
+
+		select {
+		case <-ctx.Done():
+			if last != nil {
+				return fmt.Errorf("webdriver: condition not met: %w", last)
+			}
+			return fmt.Errorf("webdriver: condition not met: %w", ctx.Err())
+		case <-tick.C:
+		}
+
+	}
+
+}
+
+// ignored reports whether the error matches any of the ignored errors.
+func ignored(err error, ignore []error) bool {
+	for _, e := range ignore {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/abcum/webdriver"
+	"github.com/abcum/webdriver/webdrivertest"
+)
+
+// blank loads an empty page at http://example.com/blank, returning its body.
+func blank(t *testing.T, srv *webdrivertest.Server, s *webdriver.Session) *webdrivertest.Node {
+	t.Helper()
+	body := webdrivertest.Elem("body", nil)
+	srv.AddPage("http://example.com/blank", &webdrivertest.Page{Title: "Blank", Root: webdrivertest.Elem("html", nil, body)})
+	if err := s.Load("http://example.com/blank"); err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+// later modifies the document, while holding the server lock, after a delay.
+func later(srv *webdrivertest.Server, fn func()) {
+	time.AfterFunc(100*time.Millisecond, func() { srv.Do(fn) })
+}
+
+// found is met when an element matching the selector is present.
+func found(css string) webdriver.Condition {
+	return func(s *webdriver.Session) (bool, error) {
+		_, err := s.Element(webdriver.FindByCss, css)
+		return err == nil, err
+	}
+}
+
+func TestWaitUntil(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			body := blank(t, srv, s)
+			later(srv, func() { body.Append(webdrivertest.Elem("p", map[string]string{"id": "late"})) })
+			opts := webdriver.WaitOptions{Timeout: 2 * time.Second, Interval: 20 * time.Millisecond, Ignore: []error{webdriver.NoSuchElement}}
+			if err := s.WaitUntil(context.Background(), found("#late"), opts); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestWaitUntilTimeout(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			_, s := serve(t, d)
+			opts := webdriver.WaitOptions{Timeout: 100 * time.Millisecond, Interval: 20 * time.Millisecond, Ignore: []error{webdriver.NoSuchElement}}
+			// The last ignored error is returned once the wait times out.
+			if err := s.WaitUntil(context.Background(), found("#never"), opts); !errors.Is(err, webdriver.NoSuchElement) {
+				t.Errorf("got %v, want %v", err, webdriver.NoSuchElement)
+			}
+			never := func(*webdriver.Session) (bool, error) { return false, nil }
+			if err := s.WaitUntil(context.Background(), never, opts); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func TestWaitUntilError(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			_, s := serve(t, d)
+			calls := 0
+			cond := func(s *webdriver.Session) (bool, error) {
+				calls++
+				return found("#never")(s)
+			}
+			opts := webdriver.WaitOptions{Timeout: time.Second, Interval: 20 * time.Millisecond}
+			if err := s.WaitUntil(context.Background(), cond, opts); !errors.Is(err, webdriver.NoSuchElement) {
+				t.Errorf("got %v, want %v", err, webdriver.NoSuchElement)
+			}
+			if calls != 1 {
+				t.Errorf("condition polled %d times, want 1", calls)
+			}
+		})
+	}
+}
+
+func TestWaitUntilCancel(t *testing.T) {
+	_, s := serve(t, webdriver.W3C)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	never := func(*webdriver.Session) (bool, error) { return false, nil }
+	if err := s.WaitUntil(ctx, never, webdriver.WaitOptions{Interval: 10 * time.Millisecond}); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
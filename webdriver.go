@@ -16,55 +16,100 @@ package webdriver
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dialect specifies the wire protocol spoken by the remote end.
+type Dialect int
+
+const (
+	// Auto negotiates the dialect with the remote end when creating a session.
+	Auto Dialect = iota
+	// JSONWire speaks the legacy Selenium JSON Wire Protocol.
+	JSONWire
+	// W3C speaks the W3C WebDriver protocol.
+	W3C
 )
 
 // Driver represents a WebDriver instance
 type Driver struct {
-	url string
-	exe string
-	cmd *exec.Cmd
+	url       string
+	base      string
+	exe       string
+	kind      Kind
+	args      []string
+	env       []string
+	dir       string
+	log       string
+	ips       []string
+	verbose   bool
+	cmd       *exec.Cmd
+	up        bool
+	stopped   bool
+	done      chan struct{}
+	exit      error
+	state     *os.ProcessState
+	out       *ring
+	lines     int
+	file      string
+	sinks     []io.Writer
+	timeout   time.Duration
+	grace     time.Duration
+	mu        sync.Mutex
+	sessions  map[string]*Session
+	dialect   Dialect
+	client    *http.Client
+	tr        *http.Transport
+	err       error
+	header    http.Header
+	auth      *url.Userinfo
+	chain     []Interceptor
+	retry     RetryPolicy
+	supervise bool
+	restarts  int
+	starting  chan struct{}
+	limit     int
+	notify    func(Event)
 }
 
-// NewDriver creates a new driver instance.
-func NewDriver(url, exe string) *Driver {
-	return &Driver{url: url, exe: exe}
-}
-
-func (w *Driver) Start() error {
-
-	if w.cmd != nil {
-		return nil
+// NewDriver creates a new driver instance. Any credentials embedded
+// within the url are removed, and sent using basic authentication. If
+// the url is empty, the driver executable is started on a free local
+// port, and the url is set when the driver is started.
+func NewDriver(addr, exe string, opts ...Option) *Driver {
+	w := &Driver{url: addr, exe: exe, client: &http.Client{}, header: make(http.Header), timeout: 20 * time.Second, grace: 5 * time.Second, lines: 100}
+	if u, err := url.Parse(addr); err == nil && u.User != nil {
+		w.auth, u.User = u.User, nil
+		w.url = u.String()
 	}
-
-	w.cmd = exec.Command(w.exe)
-
-	return w.cmd.Start()
-
-}
-
-func (w *Driver) Stop() error {
-
-	if w.cmd == nil {
-		return nil
+	w.base = w.url
+	for _, opt := range opts {
+		opt(w)
 	}
-
-	w.cmd.Process.Signal(os.Interrupt)
-
-	w.cmd = nil
-
-	return nil
-
+	return w
 }
 
 // Session creates a new WebDriver session, launching a new remote browser instance.
 func (w *Driver) Session(desired, required map[string]interface{}) (*Session, error) {
+	return w.SessionContext(context.Background(), desired, required)
+}
+
+// SessionContext creates a new WebDriver session using the specified context.
+// The context is only used while creating the session; use Session.WithContext
+// to bind a context to subsequent commands.
+func (w *Driver) SessionContext(ctx context.Context, desired, required map[string]interface{}) (*Session, error) {
 
 	if desired == nil {
 		desired = make(map[string]interface{})
@@ -74,174 +119,282 @@ func (w *Driver) Session(desired, required map[string]interface{}) (*Session, er
 		required = make(map[string]interface{})
 	}
 
-	opt := map[string]interface{}{
-		"desiredCapabilities":  desired,
-		"requiredCapabilities": required,
+	opt := make(map[string]interface{})
+
+	if w.dialect != W3C {
+		opt["desiredCapabilities"] = desired
+		opt["requiredCapabilities"] = required
+	}
+
+	if w.dialect != JSONWire {
+		opt["capabilities"] = map[string]interface{}{
+			"alwaysMatch": required,
+			"firstMatch":  []interface{}{desired},
+		}
 	}
 
-	id, res, err := w.post("/session", opt)
+	return w.create(ctx, opt)
+
+}
+
+// create sends the new session command, parsing the response according to
+// the dialect spoken by the remote end.
+func (w *Driver) create(ctx context.Context, opt map[string]interface{}) (*Session, error) {
+
+	obj, err := w.send(ctx, "POST", "/session", opt)
 	if err != nil {
 		return nil, err
 	}
 
+	if obj.dialect() == W3C {
+		var out struct {
+			ID string                 `json:"sessionId"`
+			CB map[string]interface{} `json:"capabilities"`
+		}
+		err = json.Unmarshal(obj.Value, &out)
+		if err != nil {
+			return nil, err
+		}
+		return w.track(&Session{wd: w, ID: out.ID, CB: out.CB, dialect: W3C}), nil
+	}
+
 	var out map[string]interface{}
-	err = json.Unmarshal(res, &out)
+	err = json.Unmarshal(obj.Value, &out)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Session{wd: w, ID: id, CB: out}, nil
+	return w.track(&Session{wd: w, ID: obj.session(), CB: out, dialect: JSONWire}), nil
+
+}
+
+// track records the session as active, so that it is deleted when the driver is stopped.
+func (w *Driver) track(s *Session) *Session {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.sessions == nil {
+		w.sessions = make(map[string]*Session)
+	}
+	w.sessions[s.ID] = s
+	return s
+}
 
+// untrack records the session as no longer active.
+func (w *Driver) untrack(s *Session) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.sessions, s.ID)
 }
 
 // Sessions returns all of the currently active browser sessions.
 func (w *Driver) Sessions() ([]*Session, error) {
+	return w.SessionsContext(context.Background())
+}
 
-	_, res, err := w.get("/sessions")
+// SessionsContext returns all of the currently active browser sessions using the specified context.
+func (w *Driver) SessionsContext(ctx context.Context) ([]*Session, error) {
+
+	obj, err := w.send(ctx, "GET", "/sessions", nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var out []*Session
-	err = json.Unmarshal(res, &out)
+	err = json.Unmarshal(obj.Value, &out)
 	if err != nil {
 		return nil, err
 	}
 
 	for i := range out {
 		out[i].wd = w
+		out[i].dialect = w.negotiated(obj)
 	}
 
 	return out, nil
 
 }
 
-func (w *Driver) del(url string, pms ...interface{}) (id string, out []byte, err error) {
-
-	var obj response
-
-	uri := w.url + fmt.Sprintf(url, pms...)
+// Attach returns the existing session with the specified ID, which may have
+// been created by another process, after checking that it is still active.
+func (w *Driver) Attach(id string) (*Session, error) {
+	return w.AttachContext(context.Background(), id)
+}
 
-	req, err := http.NewRequest("DELETE", uri, nil)
-	if err != nil {
-		return "", nil, err
+// AttachContext returns the existing session with the specified ID using the
+// specified context. The negotiated capabilities of the session are fetched
+// when the remote end supports it. Attached sessions are not deleted when the
+// driver is stopped.
+func (w *Driver) AttachContext(ctx context.Context, id string) (*Session, error) {
+
+	obj, err := w.send(ctx, "GET", "/session/%s", nil, id)
+
+	// W3C remote ends are not required to support fetching capabilities.
+	if errors.Is(err, UnknownCommand) || errors.Is(err, UnknownMethod) {
+		obj, err = w.send(ctx, "GET", "/session/%s/url", nil, id)
+		if err != nil {
+			return nil, err
+		}
+		return &Session{wd: w, ID: id, dialect: w.negotiated(obj)}, nil
 	}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-charset", "utf-8")
-
-	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
-	buf, err := ioutil.ReadAll(res.Body)
+	var out map[string]interface{}
+	err = json.Unmarshal(obj.Value, &out)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
-	err = json.Unmarshal(buf, &obj)
+	return &Session{wd: w, ID: id, CB: out, dialect: w.negotiated(obj)}, nil
 
-	if res.StatusCode == 200 && err != nil {
-		return "", nil, errors.New("error: response must be a JSON object")
-	}
+}
 
-	if res.StatusCode >= 400 || obj.Status != 0 {
-		return "", nil, oops(res.StatusCode, &obj)
+// negotiated returns the dialect of the remote end, unless a dialect was forced.
+func (w *Driver) negotiated(obj *response) Dialect {
+	if w.dialect != Auto {
+		return w.dialect
 	}
-
-	sid := string(bytes.Trim(obj.SessionId, "{}\""))
-
-	return sid, []byte(obj.Value), nil
-
+	return obj.dialect()
 }
 
-func (w *Driver) get(url string, pms ...interface{}) (id string, out []byte, err error) {
-
-	var obj response
-
-	uri := w.url + fmt.Sprintf(url, pms...)
-
-	req, err := http.NewRequest("GET", uri, nil)
+func (w *Driver) del(ctx context.Context, path string, pms ...interface{}) (id string, out []byte, err error) {
+	obj, err := w.send(ctx, "DELETE", path, nil, pms...)
 	if err != nil {
 		return "", nil, err
 	}
+	return obj.session(), []byte(obj.Value), nil
+}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-charset", "utf-8")
-
-	res, err := http.DefaultClient.Do(req)
+func (w *Driver) get(ctx context.Context, path string, pms ...interface{}) (id string, out []byte, err error) {
+	obj, err := w.send(ctx, "GET", path, nil, pms...)
 	if err != nil {
 		return "", nil, err
 	}
+	return obj.session(), []byte(obj.Value), nil
+}
 
-	buf, err := ioutil.ReadAll(res.Body)
+func (w *Driver) post(ctx context.Context, path string, opt map[string]interface{}, pms ...interface{}) (id string, out []byte, err error) {
+	if opt == nil {
+		opt = make(map[string]interface{})
+	}
+	obj, err := w.send(ctx, "POST", path, opt, pms...)
 	if err != nil {
 		return "", nil, err
 	}
+	return obj.session(), []byte(obj.Value), nil
+}
 
-	err = json.Unmarshal(buf, &obj)
+func (w *Driver) send(ctx context.Context, method, path string, opt map[string]interface{}, pms ...interface{}) (*response, error) {
 
-	if res.StatusCode == 200 && err != nil {
-		return "", nil, errors.New("error: response must be a JSON object")
+	cmd := &Command{Method: method, Path: fmt.Sprintf(path, pms...), Payload: opt}
+
+	if strings.HasPrefix(path, "/session/%s") {
+		cmd.Session = fmt.Sprint(pms[0])
 	}
 
-	if res.StatusCode >= 400 || obj.Status != 0 {
-		return "", nil, oops(res.StatusCode, &obj)
+	cmd.secret, _ = ctx.Value(secretKey{}).(Redaction)
+
+	if cmd.secret != 0 && opt != nil {
+		cmd.plain, cmd.Payload = opt, hide(opt, cmd.secret, cmd.secret)
 	}
 
-	sid := string(bytes.Trim(obj.SessionId, "{}\""))
+	next := w.invoke
+
+	for i := len(w.chain) - 1; i >= 0; i-- {
+		next = intercept(w.chain[i], next)
+	}
 
-	return sid, []byte(obj.Value), nil
+	for attempt := 1; ; attempt++ {
+		err := next(ctx, cmd)
+		if err == nil {
+			return cmd.res, nil
+		}
+		if !w.retry.retries(cmd, err, attempt) {
+			return nil, err
+		}
+		if w.retry.wait(ctx, attempt) != nil {
+			return nil, err
+		}
+		cmd.Status, cmd.Result, cmd.res = 0, nil, nil
+	}
 
 }
 
-func (w *Driver) post(url string, opt map[string]interface{}, pms ...interface{}) (id string, out []byte, err error) {
+func (w *Driver) invoke(ctx context.Context, cmd *Command) error {
+
+	if w.err != nil {
+		return w.err
+	}
 
 	var obj response
 
-	if opt == nil {
-		opt = make(map[string]interface{})
+	var body io.Reader
+
+	now := time.Now()
+
+	defer func() {
+		cmd.Duration = time.Since(now)
+	}()
+
+	if cmd.Payload != nil {
+		jsn, err := json.Marshal(cmd.payload())
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(jsn)
 	}
 
-	jsn, err := json.Marshal(opt)
+	req, err := http.NewRequestWithContext(ctx, cmd.Method, w.URL()+cmd.Path, body)
 	if err != nil {
-		return "", nil, err
+		return err
 	}
 
-	uri := w.url + fmt.Sprintf(url, pms...)
+	if cmd.Payload != nil {
+		req.Header.Add("Content-Type", "application/json;charset=utf-8")
+	}
 
-	req, err := http.NewRequest("POST", uri, bytes.NewReader(jsn))
-	if err != nil {
-		return "", nil, err
+	for k, v := range w.header {
+		req.Header[k] = v
+	}
+
+	if w.auth != nil {
+		pass, _ := w.auth.Password()
+		req.SetBasicAuth(w.auth.Username(), pass)
 	}
 
-	req.Header.Add("Content-Type", "application/json;charset=utf-8")
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Accept-charset", "utf-8")
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := w.client.Do(req)
 	if err != nil {
-		return "", nil, err
+		return err
 	}
 
+	defer res.Body.Close()
+
+	cmd.Status = res.StatusCode
+
 	buf, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return "", nil, err
+		return err
 	}
 
 	err = json.Unmarshal(buf, &obj)
 
 	if res.StatusCode == 200 && err != nil {
-		return "", nil, errors.New("error: response must be a JSON object")
+		return errors.New("error: response must be a JSON object")
 	}
 
-	if res.StatusCode >= 400 || obj.Status != 0 {
-		return "", nil, oops(res.StatusCode, &obj)
+	if res.StatusCode >= 400 || obj.status() != 0 {
+		return oops(res.StatusCode, &obj, buf)
 	}
 
-	sid := string(bytes.Trim(obj.SessionId, "{}\""))
+	cmd.Result = obj.Value
+
+	cmd.res = &obj
 
-	return sid, []byte(obj.Value), nil
+	return nil
 
 }
@@ -0,0 +1,163 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdriver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/abcum/webdriver"
+	"github.com/abcum/webdriver/webdrivertest"
+)
+
+// dialects are the protocol dialects every test is run against.
+var dialects = []webdriver.Dialect{webdriver.JSONWire, webdriver.W3C}
+
+func name(d webdriver.Dialect) string {
+	if d == webdriver.W3C {
+		return "W3C"
+	}
+	return "JSONWire"
+}
+
+// page returns a login page used by the tests.
+func page() *webdrivertest.Page {
+	return &webdrivertest.Page{Title: "Login", Root: webdrivertest.Elem("html", nil,
+		webdrivertest.Elem("body", nil,
+			webdrivertest.Elem("form", map[string]string{"id": "login", "class": "form wide"},
+				webdrivertest.Elem("input", map[string]string{"name": "user"}),
+				&webdrivertest.Node{Tag: "a", Text: "Forgotten password", Attrs: map[string]string{"href": "#"}},
+				&webdrivertest.Node{Tag: "span", Text: "hidden", Hidden: true},
+			),
+			webdrivertest.Elem("div", map[string]string{"id": "9lives"}),
+		))}
+}
+
+// serve starts a server speaking the dialect, serving the login page at
+// http://example.com/login, and returns a session which has loaded it.
+func serve(t *testing.T, d webdriver.Dialect, opts ...webdriver.Option) (*webdrivertest.Server, *webdriver.Session) {
+	t.Helper()
+	srv := webdrivertest.NewUnstartedServer()
+	srv.Dialect = d
+	srv.Start()
+	t.Cleanup(srv.Close)
+	srv.AddPage("http://example.com/login", page())
+	s, err := srv.Driver(opts...).Session(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Load("http://example.com/login"); err != nil {
+		t.Fatal(err)
+	}
+	return srv, s
+}
+
+func TestSessionNegotiation(t *testing.T) {
+	tests := []struct {
+		server, client, want webdriver.Dialect
+	}{
+		{webdriver.Auto, webdriver.Auto, webdriver.W3C},
+		{webdriver.JSONWire, webdriver.Auto, webdriver.JSONWire},
+		{webdriver.W3C, webdriver.Auto, webdriver.W3C},
+		{webdriver.Auto, webdriver.JSONWire, webdriver.JSONWire},
+		{webdriver.Auto, webdriver.W3C, webdriver.W3C},
+		{webdriver.JSONWire, webdriver.JSONWire, webdriver.JSONWire},
+		{webdriver.W3C, webdriver.W3C, webdriver.W3C},
+	}
+	for _, test := range tests {
+		srv := webdrivertest.NewUnstartedServer()
+		srv.Dialect = test.server
+		srv.Start()
+		defer srv.Close()
+		d := srv.Driver(webdriver.WithDialect(test.client))
+		s, err := d.Session(map[string]interface{}{"browserName": "firefox"}, nil)
+		if err != nil {
+			t.Fatalf("server %v, client %v: %v", test.server, test.client, err)
+		}
+		if s.Dialect() != test.want {
+			t.Errorf("server %v, client %v: got dialect %v, want %v", test.server, test.client, s.Dialect(), test.want)
+		}
+		if s.CB["browserName"] != "firefox" {
+			t.Errorf("server %v, client %v: got capabilities %v", test.server, test.client, s.CB)
+		}
+		all, err := d.Sessions()
+		if err != nil || len(all) != 1 || all[0].ID != s.ID || all[0].Dialect() != test.want {
+			t.Errorf("server %v, client %v: got sessions %v, %v", test.server, test.client, all, err)
+		}
+		if err := s.Delete(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Title(); !errors.Is(err, webdriver.InvalidSessionID) {
+			t.Errorf("server %v, client %v: got %v after delete, want %v", test.server, test.client, err, webdriver.InvalidSessionID)
+		}
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			_, s := serve(t, d)
+			if title, err := s.Title(); err != nil || title != "Login" {
+				t.Errorf("got title %q, %v", title, err)
+			}
+			if url, err := s.Url(); err != nil || url != "http://example.com/login" {
+				t.Errorf("got url %q, %v", url, err)
+			}
+			if err := s.Back(); err != nil {
+				t.Fatal(err)
+			}
+			if url, _ := s.Url(); url != "about:blank" {
+				t.Errorf("got url %q after back", url)
+			}
+			if err := s.Forward(); err != nil {
+				t.Fatal(err)
+			}
+			if url, _ := s.Url(); url != "http://example.com/login" {
+				t.Errorf("got url %q after forward", url)
+			}
+		})
+	}
+}
+
+func TestFaults(t *testing.T) {
+	for _, d := range dialects {
+		t.Run(name(d), func(t *testing.T) {
+			srv, s := serve(t, d)
+			srv.Inject(webdrivertest.Fault{Method: "GET", Path: "/title", Code: webdriver.JavascriptError, Times: 1})
+			_, err := s.Title()
+			if !errors.Is(err, webdriver.JavascriptError) {
+				t.Fatalf("got %v, want %v", err, webdriver.JavascriptError)
+			}
+			var e *webdriver.Error
+			if !errors.As(err, &e) || e.Message == "" || e.HTTPStatus != 500 {
+				t.Errorf("got error %#v", e)
+			}
+			if d == webdriver.JSONWire && e.Status != 17 {
+				t.Errorf("got status %d, want 17", e.Status)
+			}
+			if _, err := s.Title(); err != nil {
+				t.Errorf("fault was not removed: %v", err)
+			}
+			srv.Inject(webdrivertest.Fault{Path: "/url", Status: 503, Times: 1})
+			if _, err := s.Url(); !errors.As(err, &e) || e.HTTPStatus != 503 {
+				t.Errorf("got %v, want HTTP status 503", err)
+			}
+			srv.Inject(webdrivertest.Fault{Path: "/source", Times: 1})
+			if _, err := s.Source(); !errors.As(err, &e) || e.HTTPStatus != 500 {
+				t.Errorf("got %v, want HTTP status 500", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdrivertest
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/abcum/webdriver"
+)
+
+// Page represents a document which is served when loading a url.
+type Page struct {
+	Title string
+	Root  *Node
+}
+
+// Rect specifies the position and size of a node within the page.
+type Rect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Node represents an element within a fake document. Nodes can be modified
+// while the server is running from within a call to Server.Do.
+type Node struct {
+	// Tag is the element tag name.
+	Tag string
+	// Text is the text content of the element, preceding its children.
+	Text string
+	// Attrs are the attributes of the element.
+	Attrs map[string]string
+	// Style are the computed css properties of the element.
+	Style map[string]string
+	// Rect is the position and size of the element.
+	Rect Rect
+	// Hidden specifies whether the element, and its children, are not displayed.
+	Hidden bool
+	// Disabled specifies whether the element is disabled.
+	Disabled bool
+	// Selected specifies whether the element is selected or checked.
+	Selected bool
+	// Children are the child elements of the element.
+	Children []*Node
+	// OnClick is called, without the server lock held, when the element is clicked.
+	OnClick func()
+	// OnSubmit is called, without the server lock held, when the form is submitted.
+	OnSubmit func()
+}
+
+// Elem returns a new node with the specified tag, attributes and children.
+func Elem(tag string, attrs map[string]string, children ...*Node) *Node {
+	return &Node{Tag: tag, Attrs: attrs, Children: children}
+}
+
+// Append adds the specified children to the node, returning the node.
+func (n *Node) Append(children ...*Node) *Node {
+	n.Children = append(n.Children, children...)
+	return n
+}
+
+func (n *Node) attr(name string) (string, bool) {
+	v, ok := n.Attrs[name]
+	return v, ok
+}
+
+func (n *Node) set(name, value string) {
+	if n.Attrs == nil {
+		n.Attrs = make(map[string]string)
+	}
+	n.Attrs[name] = value
+}
+
+func (n *Node) classes() []string {
+	return strings.Fields(n.Attrs["class"])
+}
+
+// path returns the ancestors of the node within the tree, ending with
+// the node itself, or nil if the node is not attached to the tree.
+func path(root, n *Node) []*Node {
+	if root == n {
+		return []*Node{root}
+	}
+	for _, c := range root.Children {
+		if p := path(c, n); p != nil {
+			return append([]*Node{root}, p...)
+		}
+	}
+	return nil
+}
+
+// text returns the visible text of the node and its children.
+func text(n *Node) string {
+	if n.Hidden {
+		return ""
+	}
+	out := n.Text
+	for _, c := range n.Children {
+		out += text(c)
+	}
+	return strings.TrimSpace(out)
+}
+
+// source returns the outer html of the node and its children.
+func source(n *Node) string {
+	var out strings.Builder
+	keys := make([]string, 0, len(n.Attrs))
+	for k := range n.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out.WriteString("<" + n.Tag)
+	for _, k := range keys {
+		fmt.Fprintf(&out, " %s=\"%s\"", k, html.EscapeString(n.Attrs[k]))
+	}
+	out.WriteString(">" + html.EscapeString(n.Text))
+	for _, c := range n.Children {
+		out.WriteString(source(c))
+	}
+	out.WriteString("</" + n.Tag + ">")
+	return out.String()
+}
+
+// find returns the descendants of the root which match the search, in document order.
+func find(root *Node, using, value string) ([]*Node, error) {
+
+	var match func(n *Node, parents []*Node) bool
+
+	switch using {
+	case "css selector":
+		sel, err := parse(value)
+		if err != nil {
+			return nil, err
+		}
+		match = sel.match
+	case "id":
+		match = func(n *Node, _ []*Node) bool { return n.Attrs["id"] == value }
+	case "name":
+		match = func(n *Node, _ []*Node) bool { return n.Attrs["name"] == value }
+	case "class name":
+		match = func(n *Node, _ []*Node) bool { return contains(n.classes(), value) }
+	case "tag name":
+		match = func(n *Node, _ []*Node) bool { return strings.EqualFold(n.Tag, value) }
+	case "link text":
+		match = func(n *Node, _ []*Node) bool { return n.Tag == "a" && text(n) == value }
+	case "partial link text":
+		match = func(n *Node, _ []*Node) bool { return n.Tag == "a" && strings.Contains(text(n), value) }
+	default:
+		return nil, &webdriver.Error{Code: webdriver.InvalidSelector, Message: "unsupported strategy: " + using}
+	}
+
+	var out []*Node
+
+	var walk func(n *Node, parents []*Node)
+
+	walk = func(n *Node, parents []*Node) {
+		parents = append(parents, n)
+		for _, c := range n.Children {
+			if match(c, parents) {
+				out = append(out, c)
+			}
+			walk(c, parents)
+		}
+	}
+
+	walk(root, nil)
+
+	return out, nil
+
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,906 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdrivertest
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/abcum/webdriver"
+)
+
+// screenshot is a base64 encoded transparent 1x1 PNG image.
+const screenshot = "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAQAAAC1HAwCAAAAC0lEQVR42mNkYAAAAAYAAjCB0C8AAAAASUVORK5CYII="
+
+// session holds the state of a single browser session.
+type session struct {
+	id       string
+	dialect  webdriver.Dialect
+	caps     map[string]interface{}
+	history  []string
+	pos      int
+	page     *Page
+	nodes    map[string]*Node
+	ids      map[*Node]string
+	active   *Node
+	cookies  []map[string]interface{}
+	storage  map[string]map[string]string
+	timeouts map[string]int
+	windows  []string
+	window   string
+	rect     Rect
+}
+
+// call holds the state of a single command.
+type call struct {
+	s      *Server
+	ws     *session
+	node   *Node
+	route  string
+	params map[string]string
+	body   map[string]interface{}
+	hooks  []func()
+}
+
+type route struct {
+	method string
+	path   string
+	fn     func(c *call) (interface{}, error)
+}
+
+var routes []route
+
+func init() {
+	routes = []route{
+		{"GET", "/status", (*call).status},
+		{"POST", "/session", (*call).create},
+		{"GET", "/sessions", (*call).list},
+		{"GET", "/session/:session", (*call).capabilities},
+		{"DELETE", "/session/:session", (*call).delete},
+		{"GET", "/session/:session/url", (*call).url},
+		{"POST", "/session/:session/url", (*call).load},
+		{"GET", "/session/:session/title", (*call).title},
+		{"GET", "/session/:session/source", (*call).source},
+		{"POST", "/session/:session/back", (*call).back},
+		{"POST", "/session/:session/forward", (*call).forward},
+		{"POST", "/session/:session/refresh", (*call).refresh},
+		{"GET", "/session/:session/timeouts", (*call).timeouts},
+		{"POST", "/session/:session/timeouts", (*call).setTimeouts},
+		{"POST", "/session/:session/timeouts/async_script", (*call).setTimeouts},
+		{"POST", "/session/:session/timeouts/implicit_wait", (*call).setTimeouts},
+		{"POST", "/session/:session/execute", (*call).execute},
+		{"POST", "/session/:session/execute_async", (*call).execute},
+		{"POST", "/session/:session/execute/sync", (*call).execute},
+		{"POST", "/session/:session/execute/async", (*call).execute},
+		{"GET", "/session/:session/screenshot", (*call).screenshot},
+		{"GET", "/session/:session/element/active", (*call).active},
+		{"POST", "/session/:session/element/active", (*call).active},
+		{"POST", "/session/:session/element", (*call).element},
+		{"POST", "/session/:session/elements", (*call).elements},
+		{"POST", "/session/:session/element/:element/element", (*call).element},
+		{"POST", "/session/:session/element/:element/elements", (*call).elements},
+		{"GET", "/session/:session/element/:element/rect", (*call).rect},
+		{"GET", "/session/:session/element/:element/size", (*call).size},
+		{"GET", "/session/:session/element/:element/location", (*call).location},
+		{"GET", "/session/:session/element/:element/name", (*call).name},
+		{"GET", "/session/:session/element/:element/text", (*call).text},
+		{"GET", "/session/:session/element/:element/attribute/:name", (*call).attribute},
+		{"GET", "/session/:session/element/:element/property/:name", (*call).property},
+		{"GET", "/session/:session/element/:element/css/:name", (*call).css},
+		{"GET", "/session/:session/element/:element/enabled", (*call).enabled},
+		{"GET", "/session/:session/element/:element/displayed", (*call).displayed},
+		{"GET", "/session/:session/element/:element/selected", (*call).selected},
+		{"GET", "/session/:session/element/:element/equal/:other", (*call).equals},
+		{"GET", "/session/:session/element/:element/equals/:other", (*call).equals},
+		{"POST", "/session/:session/element/:element/clear", (*call).clear},
+		{"POST", "/session/:session/element/:element/click", (*call).click},
+		{"POST", "/session/:session/element/:element/submit", (*call).submit},
+		{"POST", "/session/:session/element/:element/value", (*call).value},
+		{"GET", "/session/:session/alert_text", (*call).alertText},
+		{"GET", "/session/:session/alert/text", (*call).alertText},
+		{"POST", "/session/:session/alert_text", (*call).alertRespond},
+		{"POST", "/session/:session/alert/text", (*call).alertRespond},
+		{"POST", "/session/:session/accept_alert", (*call).alertClose},
+		{"POST", "/session/:session/alert/accept", (*call).alertClose},
+		{"POST", "/session/:session/dismiss_alert", (*call).alertClose},
+		{"POST", "/session/:session/alert/dismiss", (*call).alertClose},
+		{"GET", "/session/:session/window", (*call).windowHandle},
+		{"GET", "/session/:session/window_handle", (*call).windowHandle},
+		{"GET", "/session/:session/window/handles", (*call).windowHandles},
+		{"GET", "/session/:session/window_handles", (*call).windowHandles},
+		{"POST", "/session/:session/window", (*call).windowSwitch},
+		{"POST", "/session/:session/window/new", (*call).windowNew},
+		{"DELETE", "/session/:session/window", (*call).windowClose},
+		{"GET", "/session/:session/window/rect", (*call).windowRect},
+		{"POST", "/session/:session/window/rect", (*call).windowResize},
+		{"POST", "/session/:session/window/minimize", (*call).windowRect},
+		{"POST", "/session/:session/window/maximize", (*call).windowRect},
+		{"POST", "/session/:session/window/:handle/size", (*call).windowResize},
+		{"POST", "/session/:session/window/:handle/minimize", (*call).windowRect},
+		{"POST", "/session/:session/window/:handle/maximize", (*call).windowRect},
+		{"GET", "/session/:session/cookie", (*call).cookies},
+		{"POST", "/session/:session/cookie", (*call).cookieSet},
+		{"DELETE", "/session/:session/cookie", (*call).cookiesClear},
+		{"GET", "/session/:session/cookie/:name", (*call).cookie},
+		{"DELETE", "/session/:session/cookie/:name", (*call).cookieClear},
+		{"GET", "/session/:session/local_storage", (*call).storageKeys},
+		{"POST", "/session/:session/local_storage", (*call).storageSet},
+		{"DELETE", "/session/:session/local_storage", (*call).storageClear},
+		{"GET", "/session/:session/local_storage/size", (*call).storageSize},
+		{"GET", "/session/:session/local_storage/key/:name", (*call).storageGet},
+		{"DELETE", "/session/:session/local_storage/key/:name", (*call).storageDel},
+		{"GET", "/session/:session/session_storage", (*call).storageKeys},
+		{"POST", "/session/:session/session_storage", (*call).storageSet},
+		{"DELETE", "/session/:session/session_storage", (*call).storageClear},
+		{"GET", "/session/:session/session_storage/size", (*call).storageSize},
+		{"GET", "/session/:session/session_storage/key/:name", (*call).storageGet},
+		{"DELETE", "/session/:session/session_storage/key/:name", (*call).storageDel},
+		{"GET", "/session/:session/application_cache/status", (*call).appcache},
+		{"POST", "/session/:session/actions", (*call).ignore},
+		{"DELETE", "/session/:session/actions", (*call).ignore},
+		{"POST", "/session/:session/moveto", (*call).ignore},
+		{"POST", "/session/:session/click", (*call).ignore},
+		{"POST", "/session/:session/buttondown", (*call).ignore},
+		{"POST", "/session/:session/buttonup", (*call).ignore},
+		{"POST", "/session/:session/doubleclick", (*call).ignore},
+		{"POST", "/session/:session/touch/:name", (*call).ignore},
+	}
+}
+
+// serve routes the command to its handler, resolving the session and element.
+func (c *call) serve(method, path string) (interface{}, error) {
+
+	var found bool
+
+	for _, r := range routes {
+
+		params, ok := match(r.path, path)
+		if !ok {
+			continue
+		}
+
+		found = true
+
+		if r.method != method {
+			continue
+		}
+
+		c.route, c.params = r.path, params
+
+		if id, ok := params["session"]; ok {
+			if c.ws = c.s.sessions[id]; c.ws == nil {
+				return nil, fail(webdriver.InvalidSessionID, "no active session with id %s", id)
+			}
+		}
+
+		if f := c.s.fault(method, path); f != nil {
+			if f.Code == "" {
+				return nil, f
+			}
+			return nil, fail(f.Code, "injected %s", f.Code)
+		}
+
+		if id, ok := params["element"]; ok {
+			node, err := c.ws.lookup(id)
+			if err != nil {
+				return nil, err
+			}
+			c.node = node
+		}
+
+		return r.fn(c)
+
+	}
+
+	if found {
+		return nil, fail(webdriver.UnknownMethod, "unknown method %s for %s", method, path)
+	}
+
+	return nil, fail(webdriver.UnknownCommand, "unknown command %s %s", method, path)
+
+}
+
+// match matches the path against the pattern, returning the named parameters.
+func match(pattern, path string) (map[string]string, bool) {
+	a := strings.Split(strings.Trim(pattern, "/"), "/")
+	b := strings.Split(strings.Trim(path, "/"), "/")
+	if len(a) != len(b) {
+		return nil, false
+	}
+	out := make(map[string]string)
+	for i := range a {
+		switch {
+		case strings.HasPrefix(a[i], ":"):
+			out[a[i][1:]] = b[i]
+		case a[i] != b[i]:
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+func (c *call) str(key string) string {
+	v, _ := c.body[key].(string)
+	return v
+}
+
+func (c *call) num(key string) (int, bool) {
+	v, ok := c.body[key].(float64)
+	return int(v), ok
+}
+
+// load navigates the session to the specified url.
+func (ws *session) load(s *Server, url string) {
+	ws.page = s.pages[url]
+	if ws.page == nil {
+		ws.page = &Page{Root: Elem("html", nil, Elem("body", nil))}
+	}
+	ws.active = nil
+}
+
+// ref returns the element reference for the node, in both protocol dialects.
+func (ws *session) ref(n *Node) map[string]string {
+	id, ok := ws.ids[n]
+	if !ok {
+		id = fmt.Sprintf("element-%d", len(ws.ids)+1)
+		ws.ids[n], ws.nodes[id] = id, n
+	}
+	return map[string]string{"ELEMENT": id, webElement: id}
+}
+
+// lookup returns the node with the specified element id, if still attached.
+func (ws *session) lookup(id string) (*Node, error) {
+	n := ws.nodes[id]
+	if n == nil {
+		return nil, fail(webdriver.NoSuchElement, "no element with id %s", id)
+	}
+	if path(ws.page.Root, n) == nil {
+		return nil, fail(webdriver.StaleElementReference, "element %s is no longer attached to the page", id)
+	}
+	return n, nil
+}
+
+// arg resolves an element reference passed as a script argument.
+func (ws *session) arg(v interface{}) (*Node, error) {
+	m, _ := v.(map[string]interface{})
+	id, ok := m[webElement].(string)
+	if !ok {
+		id, _ = m["ELEMENT"].(string)
+	}
+	return ws.lookup(id)
+}
+
+func (c *call) status() (interface{}, error) {
+	return map[string]interface{}{
+		"ready":   true,
+		"message": "webdrivertest is ready",
+		"build":   map[string]interface{}{"version": "webdrivertest"},
+		"os":      map[string]interface{}{"name": runtime.GOOS, "arch": runtime.GOARCH},
+	}, nil
+}
+
+func (c *call) create() (interface{}, error) {
+
+	caps := make(map[string]interface{})
+
+	merge := func(v interface{}) {
+		if m, ok := v.(map[string]interface{}); ok {
+			for k, v := range m {
+				caps[k] = v
+			}
+		}
+	}
+
+	d := c.s.Dialect
+
+	switch w3c, ok := c.body["capabilities"].(map[string]interface{}); {
+	case ok && d != webdriver.JSONWire:
+		d = webdriver.W3C
+		merge(w3c["alwaysMatch"])
+		if first, ok := w3c["firstMatch"].([]interface{}); ok && len(first) > 0 {
+			merge(first[0])
+		}
+	case d == webdriver.W3C:
+		return nil, fail(webdriver.SessionNotCreated, "missing capabilities")
+	default:
+		d = webdriver.JSONWire
+		merge(c.body["desiredCapabilities"])
+		merge(c.body["requiredCapabilities"])
+	}
+
+	if _, ok := caps["browserName"]; !ok {
+		caps["browserName"] = "webdrivertest"
+	}
+
+	if d == webdriver.W3C {
+		caps["browserVersion"] = "1.0"
+		caps["platformName"] = runtime.GOOS
+	} else {
+		caps["version"] = "1.0"
+		caps["platform"] = runtime.GOOS
+	}
+
+	c.s.seq++
+
+	c.ws = &session{
+		id:       fmt.Sprintf("session-%d", c.s.seq),
+		dialect:  d,
+		caps:     caps,
+		history:  []string{"about:blank"},
+		nodes:    make(map[string]*Node),
+		ids:      make(map[*Node]string),
+		storage:  map[string]map[string]string{"localStorage": {}, "sessionStorage": {}},
+		timeouts: map[string]int{"script": 30000, "pageLoad": 300000, "implicit": 0},
+		windows:  []string{"window-1"},
+		window:   "window-1",
+		rect:     Rect{Width: 1024, Height: 768},
+	}
+
+	c.ws.load(c.s, "about:blank")
+
+	c.s.sessions[c.ws.id] = c.ws
+
+	if d == webdriver.W3C {
+		return map[string]interface{}{"sessionId": c.ws.id, "capabilities": caps}, nil
+	}
+
+	return caps, nil
+
+}
+
+func (c *call) capabilities() (interface{}, error) {
+	return c.ws.caps, nil
+}
+
+func (c *call) list() (interface{}, error) {
+	out := []interface{}{}
+	for id, ws := range c.s.sessions {
+		out = append(out, map[string]interface{}{"id": id, "capabilities": ws.caps})
+	}
+	return out, nil
+}
+
+func (c *call) delete() (interface{}, error) {
+	delete(c.s.sessions, c.ws.id)
+	return nil, nil
+}
+
+func (c *call) url() (interface{}, error) {
+	return c.ws.history[c.ws.pos], nil
+}
+
+func (c *call) load() (interface{}, error) {
+	url := c.str("url")
+	if url == "" {
+		return nil, fail(webdriver.InvalidArgument, "missing url")
+	}
+	c.ws.history = append(c.ws.history[:c.ws.pos+1], url)
+	c.ws.pos++
+	c.ws.load(c.s, url)
+	return nil, nil
+}
+
+func (c *call) title() (interface{}, error) {
+	return c.ws.page.Title, nil
+}
+
+func (c *call) source() (interface{}, error) {
+	return source(c.ws.page.Root), nil
+}
+
+func (c *call) back() (interface{}, error) {
+	if c.ws.pos > 0 {
+		c.ws.pos--
+		c.ws.load(c.s, c.ws.history[c.ws.pos])
+	}
+	return nil, nil
+}
+
+func (c *call) forward() (interface{}, error) {
+	if c.ws.pos < len(c.ws.history)-1 {
+		c.ws.pos++
+		c.ws.load(c.s, c.ws.history[c.ws.pos])
+	}
+	return nil, nil
+}
+
+func (c *call) refresh() (interface{}, error) {
+	c.ws.load(c.s, c.ws.history[c.ws.pos])
+	return nil, nil
+}
+
+func (c *call) timeouts() (interface{}, error) {
+	return c.ws.timeouts, nil
+}
+
+func (c *call) setTimeouts() (interface{}, error) {
+	ms, ok := c.num("ms")
+	switch kind := c.str("type"); {
+	case strings.HasSuffix(c.route, "/async_script"):
+		c.ws.timeouts["script"] = ms
+	case strings.HasSuffix(c.route, "/implicit_wait"):
+		c.ws.timeouts["implicit"] = ms
+	case ok && kind == "page load":
+		c.ws.timeouts["pageLoad"] = ms
+	case ok && (kind == "script" || kind == "implicit"):
+		c.ws.timeouts[kind] = ms
+	case ok:
+		return nil, fail(webdriver.InvalidArgument, "unknown timeout type %q", kind)
+	default:
+		for _, k := range []string{"script", "pageLoad", "implicit"} {
+			if v, ok := c.num(k); ok {
+				c.ws.timeouts[k] = v
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (c *call) execute() (interface{}, error) {
+
+	script := c.str("script")
+
+	args, _ := c.body["args"].([]interface{})
+
+	switch {
+	case strings.Contains(script, "window[arguments[0]]"):
+		return c.storageScript(script, args)
+	case strings.Contains(script, "window.applicationCache"):
+		return 0, nil
+	case strings.Contains(script, "nodeName != 'FORM'"):
+		if len(args) == 0 {
+			return nil, fail(webdriver.JavascriptError, "missing element")
+		}
+		n, err := c.ws.arg(args[0])
+		if err != nil {
+			return nil, err
+		}
+		if err := c.submitForm(n); err != nil {
+			return nil, fail(webdriver.JavascriptError, "%v", err)
+		}
+		return nil, nil
+	}
+
+	if c.s.script != nil {
+		return c.s.script(script, args)
+	}
+
+	return nil, nil
+
+}
+
+func (c *call) storageScript(script string, args []interface{}) (interface{}, error) {
+	str := func(i int) string {
+		if i < len(args) {
+			v, _ := args[i].(string)
+			return v
+		}
+		return ""
+	}
+	area := c.ws.storage[str(0)]
+	if area == nil {
+		return nil, fail(webdriver.JavascriptError, "unknown storage area %q", str(0))
+	}
+	switch {
+	case strings.Contains(script, ".length"):
+		return len(area), nil
+	case strings.Contains(script, ".clear()"):
+		for k := range area {
+			delete(area, k)
+		}
+	case strings.Contains(script, "Object.keys"):
+		return keys(area), nil
+	case strings.Contains(script, ".getItem("):
+		if v, ok := area[str(1)]; ok {
+			return v, nil
+		}
+	case strings.Contains(script, ".removeItem("):
+		delete(area, str(1))
+	case strings.Contains(script, ".setItem("):
+		area[str(1)] = str(2)
+	}
+	return nil, nil
+}
+
+func (c *call) screenshot() (interface{}, error) {
+	return screenshot, nil
+}
+
+func (c *call) active() (interface{}, error) {
+	if c.ws.active != nil && path(c.ws.page.Root, c.ws.active) != nil {
+		return c.ws.ref(c.ws.active), nil
+	}
+	if body, _ := find(c.ws.page.Root, "tag name", "body"); len(body) > 0 {
+		return c.ws.ref(body[0]), nil
+	}
+	return c.ws.ref(c.ws.page.Root), nil
+}
+
+func (c *call) search() ([]*Node, error) {
+	root := c.ws.page.Root
+	if c.node != nil {
+		root = c.node
+	}
+	return find(root, c.str("using"), c.str("value"))
+}
+
+func (c *call) element() (interface{}, error) {
+	out, err := c.search()
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fail(webdriver.NoSuchElement, "no element found using %s %q", c.str("using"), c.str("value"))
+	}
+	return c.ws.ref(out[0]), nil
+}
+
+func (c *call) elements() (interface{}, error) {
+	out, err := c.search()
+	if err != nil {
+		return nil, err
+	}
+	refs := []interface{}{}
+	for _, n := range out {
+		refs = append(refs, c.ws.ref(n))
+	}
+	return refs, nil
+}
+
+func (c *call) rect() (interface{}, error) {
+	return c.node.Rect, nil
+}
+
+func (c *call) size() (interface{}, error) {
+	return map[string]interface{}{"width": c.node.Rect.Width, "height": c.node.Rect.Height}, nil
+}
+
+func (c *call) location() (interface{}, error) {
+	return map[string]interface{}{"x": c.node.Rect.X, "y": c.node.Rect.Y}, nil
+}
+
+func (c *call) name() (interface{}, error) {
+	return strings.ToLower(c.node.Tag), nil
+}
+
+func (c *call) text() (interface{}, error) {
+	return text(c.node), nil
+}
+
+func (c *call) attribute() (interface{}, error) {
+	if c.params["name"] == "outerHTML" {
+		return source(c.node), nil
+	}
+	if v, ok := c.node.attr(c.params["name"]); ok {
+		return v, nil
+	}
+	return nil, nil
+}
+
+func (c *call) property() (interface{}, error) {
+	switch c.params["name"] {
+	case "outerHTML":
+		return source(c.node), nil
+	case "innerHTML":
+		out := c.node.Text
+		for _, n := range c.node.Children {
+			out += source(n)
+		}
+		return out, nil
+	case "textContent":
+		return text(c.node), nil
+	case "checked", "selected":
+		return c.node.Selected, nil
+	case "disabled":
+		return c.node.Disabled, nil
+	}
+	return c.attribute()
+}
+
+func (c *call) css() (interface{}, error) {
+	return c.node.Style[c.params["name"]], nil
+}
+
+func (c *call) enabled() (interface{}, error) {
+	return !c.node.Disabled, nil
+}
+
+func (c *call) displayed() (interface{}, error) {
+	return c.visible(), nil
+}
+
+// visible reports whether the element and all of its ancestors are displayed.
+func (c *call) visible() bool {
+	for _, n := range path(c.ws.page.Root, c.node) {
+		if n.Hidden {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *call) selected() (interface{}, error) {
+	return c.node.Selected, nil
+}
+
+func (c *call) equals() (interface{}, error) {
+	o, err := c.ws.lookup(c.params["other"])
+	if err != nil {
+		return nil, err
+	}
+	return o == c.node, nil
+}
+
+// interactable returns an error if the element can not be interacted with.
+func (c *call) interactable() error {
+	if !c.visible() {
+		return fail(webdriver.ElementNotInteractable, "element is not displayed")
+	}
+	return nil
+}
+
+func (c *call) clear() (interface{}, error) {
+	if err := c.interactable(); err != nil {
+		return nil, err
+	}
+	if c.node.Disabled {
+		return nil, fail(webdriver.InvalidElementState, "element is disabled")
+	}
+	c.node.set("value", "")
+	return nil, nil
+}
+
+func (c *call) click() (interface{}, error) {
+	if err := c.interactable(); err != nil {
+		return nil, err
+	}
+	c.ws.active = c.node
+	if !c.node.Disabled {
+		switch t := c.node.Attrs["type"]; {
+		case c.node.Tag == "option", t == "radio":
+			c.node.Selected = true
+		case t == "checkbox":
+			c.node.Selected = !c.node.Selected
+		case t == "submit":
+			if err := c.submitForm(c.node); err != nil {
+				return nil, err
+			}
+		}
+		if c.node.OnClick != nil {
+			c.hooks = append(c.hooks, c.node.OnClick)
+		}
+	}
+	return nil, nil
+}
+
+func (c *call) submit() (interface{}, error) {
+	return nil, c.submitForm(c.node)
+}
+
+// submitForm submits the form enclosing the specified node.
+func (c *call) submitForm(n *Node) error {
+	p := path(c.ws.page.Root, n)
+	for i := len(p) - 1; i >= 0; i-- {
+		if p[i].Tag == "form" {
+			if p[i].OnSubmit != nil {
+				c.hooks = append(c.hooks, p[i].OnSubmit)
+			}
+			return nil
+		}
+	}
+	return fail(webdriver.UnknownError, "element is not within a form")
+}
+
+func (c *call) value() (interface{}, error) {
+	if err := c.interactable(); err != nil {
+		return nil, err
+	}
+	if c.node.Disabled {
+		return nil, fail(webdriver.InvalidElementState, "element is disabled")
+	}
+	keys := c.str("text")
+	if keys == "" {
+		list, _ := c.body["value"].([]interface{})
+		for _, k := range list {
+			v, _ := k.(string)
+			keys += v
+		}
+	}
+	c.ws.active = c.node
+	c.node.set("value", c.node.Attrs["value"]+keys)
+	return nil, nil
+}
+
+func (c *call) alertText() (interface{}, error) {
+	if c.s.alert == nil {
+		return nil, fail(webdriver.NoSuchAlert, "no alert is open")
+	}
+	return *c.s.alert, nil
+}
+
+func (c *call) alertRespond() (interface{}, error) {
+	if c.s.alert == nil {
+		return nil, fail(webdriver.NoSuchAlert, "no alert is open")
+	}
+	return nil, nil
+}
+
+func (c *call) alertClose() (interface{}, error) {
+	if c.s.alert == nil {
+		return nil, fail(webdriver.NoSuchAlert, "no alert is open")
+	}
+	c.s.alert = nil
+	return nil, nil
+}
+
+func (c *call) windowHandle() (interface{}, error) {
+	if c.ws.window == "" {
+		return nil, fail(webdriver.NoSuchWindow, "the current window is closed")
+	}
+	return c.ws.window, nil
+}
+
+func (c *call) windowHandles() (interface{}, error) {
+	return append([]string{}, c.ws.windows...), nil
+}
+
+func (c *call) windowSwitch() (interface{}, error) {
+	h := c.str("handle")
+	if h == "" {
+		h = c.str("name")
+	}
+	if !contains(c.ws.windows, h) {
+		return nil, fail(webdriver.NoSuchWindow, "no window with handle %s", h)
+	}
+	c.ws.window = h
+	return nil, nil
+}
+
+func (c *call) windowNew() (interface{}, error) {
+	c.s.seq++
+	h := fmt.Sprintf("window-%d", c.s.seq)
+	c.ws.windows = append(c.ws.windows, h)
+	return map[string]interface{}{"handle": h, "type": "tab"}, nil
+}
+
+func (c *call) windowClose() (interface{}, error) {
+	if _, err := c.windowHandle(); err != nil {
+		return nil, err
+	}
+	for i, h := range c.ws.windows {
+		if h == c.ws.window {
+			c.ws.windows = append(c.ws.windows[:i], c.ws.windows[i+1:]...)
+			break
+		}
+	}
+	c.ws.window = ""
+	return c.windowHandles()
+}
+
+func (c *call) windowRect() (interface{}, error) {
+	if _, err := c.windowHandle(); err != nil {
+		return nil, err
+	}
+	return c.ws.rect, nil
+}
+
+func (c *call) windowResize() (interface{}, error) {
+	if w, ok := c.num("width"); ok {
+		c.ws.rect.Width = float64(w)
+	}
+	if h, ok := c.num("height"); ok {
+		c.ws.rect.Height = float64(h)
+	}
+	return c.windowRect()
+}
+
+func (c *call) cookies() (interface{}, error) {
+	return append([]map[string]interface{}{}, c.ws.cookies...), nil
+}
+
+func (c *call) cookie() (interface{}, error) {
+	for _, v := range c.ws.cookies {
+		if v["name"] == c.params["name"] {
+			return v, nil
+		}
+	}
+	return nil, fail(webdriver.NoSuchCookie, "no cookie named %s", c.params["name"])
+}
+
+func (c *call) cookieSet() (interface{}, error) {
+	v, _ := c.body["cookie"].(map[string]interface{})
+	if name, _ := v["name"].(string); name == "" {
+		return nil, fail(webdriver.InvalidArgument, "missing cookie name")
+	}
+	c.params["name"] = v["name"].(string)
+	c.cookieClear()
+	c.ws.cookies = append(c.ws.cookies, v)
+	return nil, nil
+}
+
+func (c *call) cookiesClear() (interface{}, error) {
+	c.ws.cookies = nil
+	return nil, nil
+}
+
+func (c *call) cookieClear() (interface{}, error) {
+	for i, v := range c.ws.cookies {
+		if v["name"] == c.params["name"] {
+			c.ws.cookies = append(c.ws.cookies[:i], c.ws.cookies[i+1:]...)
+			break
+		}
+	}
+	return nil, nil
+}
+
+// storageArea returns the storage area addressed by the JSON Wire command.
+func (c *call) storageArea() map[string]string {
+	if strings.Contains(c.route, "/local_storage") {
+		return c.ws.storage["localStorage"]
+	}
+	return c.ws.storage["sessionStorage"]
+}
+
+func (c *call) storageKeys() (interface{}, error) {
+	return keys(c.storageArea()), nil
+}
+
+func (c *call) storageSet() (interface{}, error) {
+	c.storageArea()[c.str("key")] = c.str("value")
+	return nil, nil
+}
+
+func (c *call) storageClear() (interface{}, error) {
+	area := c.storageArea()
+	for k := range area {
+		delete(area, k)
+	}
+	return nil, nil
+}
+
+func (c *call) storageSize() (interface{}, error) {
+	return len(c.storageArea()), nil
+}
+
+func (c *call) storageGet() (interface{}, error) {
+	if v, ok := c.storageArea()[c.params["name"]]; ok {
+		return v, nil
+	}
+	return nil, nil
+}
+
+func (c *call) storageDel() (interface{}, error) {
+	delete(c.storageArea(), c.params["name"])
+	return nil, nil
+}
+
+func (c *call) appcache() (interface{}, error) {
+	return 0, nil
+}
+
+func (c *call) ignore() (interface{}, error) {
+	return nil, nil
+}
+
+func keys(m map[string]string) []string {
+	out := []string{}
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,291 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webdrivertest
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/abcum/webdriver"
+)
+
+// compound is a sequence of simple selectors which must all match a node.
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   [][2]string
+	exists  []string
+	child   bool
+}
+
+// selector is a chain of compound selectors, separated by combinators.
+type selector []compound
+
+// group is a comma separated list of selectors.
+type group []selector
+
+// parse parses the subset of css selectors supported by the fake document:
+// type, universal, id, class and attribute selectors, combined using the
+// descendant and child combinators, in comma separated groups.
+func parse(value string) (group, error) {
+	p := &parser{s: []rune(value)}
+	var out group
+	for {
+		sel, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sel)
+		p.space()
+		if p.eof() {
+			return out, nil
+		}
+		if !p.accept(',') {
+			return nil, p.fail()
+		}
+	}
+}
+
+func (g group) match(n *Node, parents []*Node) bool {
+	for _, sel := range g {
+		if sel.match(len(sel)-1, n, parents) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s selector) match(i int, n *Node, parents []*Node) bool {
+	if !s[i].match(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if s[i].child {
+		return len(parents) > 0 && s.match(i-1, parents[len(parents)-1], parents[:len(parents)-1])
+	}
+	for j := len(parents) - 1; j >= 0; j-- {
+		if s.match(i-1, parents[j], parents[:j]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *compound) match(n *Node) bool {
+	if c.tag != "" && c.tag != "*" && !strings.EqualFold(c.tag, n.Tag) {
+		return false
+	}
+	if c.id != "" && n.Attrs["id"] != c.id {
+		return false
+	}
+	for _, v := range c.classes {
+		if !contains(n.classes(), v) {
+			return false
+		}
+	}
+	for _, v := range c.exists {
+		if _, ok := n.attr(v); !ok {
+			return false
+		}
+	}
+	for _, v := range c.attrs {
+		if a, ok := n.attr(v[0]); !ok || a != v[1] {
+			return false
+		}
+	}
+	return true
+}
+
+type parser struct {
+	s []rune
+	i int
+}
+
+func (p *parser) eof() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *parser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *parser) accept(r rune) bool {
+	if !p.eof() && p.peek() == r {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) space() bool {
+	n := p.i
+	for !p.eof() && unicode.IsSpace(p.peek()) {
+		p.i++
+	}
+	return p.i > n
+}
+
+func (p *parser) fail() error {
+	return &webdriver.Error{Code: webdriver.InvalidSelector, Message: "invalid css selector: " + string(p.s)}
+}
+
+func (p *parser) selector() (selector, error) {
+	var out selector
+	var child bool
+	p.space()
+	for {
+		c, err := p.compound()
+		if err != nil {
+			return nil, err
+		}
+		c.child = child
+		out = append(out, c)
+		space := p.space()
+		switch {
+		case p.eof(), p.peek() == ',':
+			return out, nil
+		case p.accept('>'):
+			p.space()
+			child = true
+		case space:
+			child = false
+		default:
+			return nil, p.fail()
+		}
+	}
+}
+
+func (p *parser) compound() (compound, error) {
+	var c compound
+	n := p.i
+	if p.accept('*') {
+		c.tag = "*"
+	} else if isIdent(p.peek()) {
+		c.tag = p.ident()
+	}
+	for {
+		switch {
+		case p.accept('#'):
+			if c.id = p.ident(); c.id == "" {
+				return c, p.fail()
+			}
+		case p.accept('.'):
+			v := p.ident()
+			if v == "" {
+				return c, p.fail()
+			}
+			c.classes = append(c.classes, v)
+		case p.accept('['):
+			p.space()
+			k := p.ident()
+			if k == "" {
+				return c, p.fail()
+			}
+			p.space()
+			if p.accept(']') {
+				c.exists = append(c.exists, k)
+				continue
+			}
+			if !p.accept('=') {
+				return c, p.fail()
+			}
+			p.space()
+			v, err := p.value()
+			if err != nil {
+				return c, err
+			}
+			p.space()
+			if !p.accept(']') {
+				return c, p.fail()
+			}
+			c.attrs = append(c.attrs, [2]string{k, v})
+		default:
+			if p.i == n {
+				return c, p.fail()
+			}
+			return c, nil
+		}
+	}
+}
+
+func (p *parser) value() (string, error) {
+	q := p.peek()
+	if q != '"' && q != '\'' {
+		return p.ident(), nil
+	}
+	p.i++
+	var out strings.Builder
+	for !p.eof() {
+		switch r := p.peek(); r {
+		case q:
+			p.i++
+			return out.String(), nil
+		case '\\':
+			out.WriteRune(p.escape())
+		default:
+			out.WriteRune(r)
+			p.i++
+		}
+	}
+	return "", p.fail()
+}
+
+func (p *parser) ident() string {
+	var out strings.Builder
+	for !p.eof() {
+		switch r := p.peek(); {
+		case r == '\\':
+			out.WriteRune(p.escape())
+		case isIdent(r):
+			out.WriteRune(r)
+			p.i++
+		default:
+			return out.String()
+		}
+	}
+	return out.String()
+}
+
+// escape decodes a css escape sequence, starting at the backslash.
+func (p *parser) escape() rune {
+	p.i++
+	if p.eof() {
+		return unicode.ReplacementChar
+	}
+	n := p.i
+	for p.i < len(p.s) && p.i-n < 6 && strings.ContainsRune("0123456789abcdefABCDEF", p.s[p.i]) {
+		p.i++
+	}
+	if p.i == n {
+		p.i++
+		return p.s[n]
+	}
+	v, _ := strconv.ParseUint(string(p.s[n:p.i]), 16, 32)
+	if !p.eof() && unicode.IsSpace(p.peek()) {
+		p.i++
+	}
+	return rune(v)
+}
+
+func isIdent(r rune) bool {
+	return r == '-' || r == '_' || r == '\\' || r > 127 || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,319 @@
+// Copyright © 2016 Abcum Ltd
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this info except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webdrivertest provides an in-process fake WebDriver remote end, serving
+// the endpoints used by the webdriver package from a scriptable document, so that
+// code built on the webdriver package can be tested without a browser.
+package webdrivertest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/abcum/webdriver"
+)
+
+// webElement is the key used by the W3C protocol to identify web elements.
+const webElement = "element-6066-11e4-a52e-4f735466cecf"
+
+// Fault is an error injected into the responses of matching commands.
+type Fault struct {
+	// Method is the HTTP method to match, or empty to match any method.
+	Method string
+	// Path is the suffix of the command path to match, or empty to match any path.
+	Path string
+	// Code is the error returned by the remote end.
+	Code webdriver.ErrorCode
+	// Status is an HTTP status returned without a JSON body, when Code is
+	// empty. If both Code and Status are empty, 500 is returned.
+	Status int
+	// Times is the number of commands to fail, or zero to fail every command.
+	Times int
+}
+
+// Script handles scripts executed within the page, other than those used
+// internally by the webdriver package. It is called with the server lock
+// held, so it must not call any of the server methods.
+type Script func(script string, args []interface{}) (interface{}, error)
+
+// Server is a fake WebDriver remote end, listening on a local loopback address.
+type Server struct {
+	// URL is the base url of the server, of the form http://ipaddr:port.
+	URL string
+	// Dialect is the protocol dialect spoken by the server. The zero value
+	// negotiates the dialect from the capabilities sent by the client. It
+	// must be set before the server is started.
+	Dialect  webdriver.Dialect
+	srv      *httptest.Server
+	mu       sync.Mutex
+	seq      int
+	pages    map[string]*Page
+	alert    *string
+	faults   []*Fault
+	script   Script
+	sessions map[string]*session
+}
+
+// NewServer starts and returns a new server.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer returns a new server which is not yet started.
+func NewUnstartedServer() *Server {
+	s := &Server{
+		pages:    make(map[string]*Page),
+		sessions: make(map[string]*session),
+	}
+	s.srv = httptest.NewUnstartedServer(s)
+	return s
+}
+
+// Start starts the server.
+func (s *Server) Start() {
+	s.srv.Start()
+	s.URL = s.srv.URL
+}
+
+// Close shuts down the server, blocking until all outstanding requests have completed.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Driver returns a driver which sends commands to the server.
+func (s *Server) Driver(opts ...webdriver.Option) *webdriver.Driver {
+	return webdriver.NewDriver(s.URL, "", opts...)
+}
+
+// AddPage serves the page when the specified url is loaded.
+func (s *Server) AddPage(url string, p *Page) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[url] = p
+}
+
+// Do calls fn with the server lock held, enabling the document to be modified safely.
+func (s *Server) Do(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn()
+}
+
+// Alert opens a dialog with the specified text, until it is accepted or dismissed.
+func (s *Server) Alert(text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alert = &text
+}
+
+// Inject fails the commands matching the fault.
+func (s *Server) Inject(f Fault) {
+	if f.Code == "" && f.Status == 0 {
+		f.Status = http.StatusInternalServerError
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// HandleScript specifies the handler for scripts executed within the page.
+func (s *Server) HandleScript(fn Script) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.script = fn
+}
+
+// Sessions returns the number of currently active sessions.
+func (s *Server) Sessions() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// ServeHTTP serves a single WebDriver command.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	c := &call{s: s}
+
+	json.NewDecoder(r.Body).Decode(&c.body)
+
+	s.mu.Lock()
+
+	val, err := c.serve(r.Method, r.URL.Path)
+
+	d := s.Dialect
+	if c.ws != nil {
+		d = c.ws.dialect
+	}
+
+	s.mu.Unlock()
+
+	var f *Fault
+	if errors.As(err, &f) {
+		http.Error(w, http.StatusText(f.Status), f.Status)
+		return
+	}
+
+	for _, fn := range c.hooks {
+		fn()
+	}
+
+	write(w, d, c.ws, val, err)
+
+}
+
+// fault returns the first injected fault matching the command.
+func (s *Server) fault(method, path string) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != method {
+			continue
+		}
+		if !strings.HasSuffix(path, f.Path) {
+			continue
+		}
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (f *Fault) Error() string {
+	return http.StatusText(f.Status)
+}
+
+func write(w http.ResponseWriter, d webdriver.Dialect, ws *session, val interface{}, err error) {
+
+	code := http.StatusOK
+
+	out := map[string]interface{}{"value": val}
+
+	if d == webdriver.JSONWire {
+		out["status"] = 0
+		out["sessionId"] = nil
+		if ws != nil {
+			out["sessionId"] = ws.id
+		}
+	}
+
+	if err != nil {
+		e := wrap(err)
+		switch d {
+		case webdriver.JSONWire:
+			code = http.StatusInternalServerError
+			if e.Code == webdriver.UnknownCommand {
+				code = http.StatusNotFound
+			}
+			out["status"] = status(e.Code)
+			out["value"] = map[string]interface{}{"message": e.Message}
+		default:
+			code = httpStatus(e.Code)
+			out["value"] = map[string]interface{}{"error": e.Code, "message": e.Message, "stacktrace": ""}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(out)
+
+}
+
+func wrap(err error) *webdriver.Error {
+	var e *webdriver.Error
+	var c webdriver.ErrorCode
+	switch {
+	case errors.As(err, &e):
+		return e
+	case errors.As(err, &c):
+		return &webdriver.Error{Code: c, Message: string(c)}
+	default:
+		return &webdriver.Error{Code: webdriver.UnknownError, Message: err.Error()}
+	}
+}
+
+func fail(code webdriver.ErrorCode, format string, args ...interface{}) error {
+	return &webdriver.Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// status returns the JSON Wire numeric status for the error code.
+func status(code webdriver.ErrorCode) int {
+	switch code {
+	case webdriver.InvalidSessionID:
+		return 6
+	case webdriver.NoSuchElement:
+		return 7
+	case webdriver.NoSuchFrame:
+		return 8
+	case webdriver.UnknownCommand, webdriver.UnknownMethod:
+		return 9
+	case webdriver.StaleElementReference:
+		return 10
+	case webdriver.ElementNotInteractable:
+		return 11
+	case webdriver.InvalidElementState:
+		return 12
+	case webdriver.JavascriptError:
+		return 17
+	case webdriver.Timeout:
+		return 21
+	case webdriver.NoSuchWindow:
+		return 23
+	case webdriver.InvalidCookieDomain:
+		return 24
+	case webdriver.UnableToSetCookie:
+		return 25
+	case webdriver.UnexpectedAlertOpen:
+		return 26
+	case webdriver.NoSuchAlert:
+		return 27
+	case webdriver.ScriptTimeout:
+		return 28
+	case webdriver.InvalidArgument:
+		return 29
+	case webdriver.InvalidSelector:
+		return 32
+	case webdriver.SessionNotCreated:
+		return 33
+	case webdriver.MoveTargetOutOfBounds:
+		return 34
+	default:
+		return 13
+	}
+}
+
+// httpStatus returns the W3C HTTP status for the error code.
+func httpStatus(code webdriver.ErrorCode) int {
+	switch code {
+	case webdriver.ElementClickIntercepted, webdriver.ElementNotInteractable, webdriver.InsecureCertificate,
+		webdriver.InvalidArgument, webdriver.InvalidCookieDomain, webdriver.InvalidElementState, webdriver.InvalidSelector:
+		return http.StatusBadRequest
+	case webdriver.InvalidSessionID, webdriver.NoSuchAlert, webdriver.NoSuchCookie, webdriver.NoSuchElement,
+		webdriver.NoSuchFrame, webdriver.NoSuchWindow, webdriver.StaleElementReference, webdriver.UnknownCommand:
+		return http.StatusNotFound
+	case webdriver.UnknownMethod:
+		return http.StatusMethodNotAllowed
+	default:
+		return http.StatusInternalServerError
+	}
+}
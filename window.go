@@ -14,33 +14,85 @@
 
 package webdriver
 
+import (
+	"context"
+)
+
 // Window represents a browser window.
 type Window struct {
 	ID string `json:"WINDOW"`
 	ws *Session
 }
 
-// Close closes the window.
+// WithContext returns a copy of the window bound to the specified context.
+func (w *Window) WithContext(ctx context.Context) *Window {
+	return &Window{ID: w.ID, ws: w.ws.WithContext(ctx)}
+}
+
+// Focus switches focus to the window, so that subsequent commands are sent to it.
+func (w *Window) Focus() error {
+	opt := map[string]interface{}{"name": w.ID}
+	if w.ws.w3c() {
+		opt = map[string]interface{}{"handle": w.ID}
+	}
+	_, _, err := w.ws.wd.post(w.ws.Context(), "/session/%s/window", opt, w.ws.ID)
+	return err
+}
+
+// focus focuses the window, unless it is the current window. The W3C protocol
+// only supports commands which act on the current window.
+func (w *Window) focus() error {
+	if w.ID == "current" {
+		return nil
+	}
+	return w.Focus()
+}
+
+// Close closes the window, focusing it first unless it is the current window.
 func (w *Window) Close() error {
-	_, _, err := w.ws.wd.del("/session/%s/window", w.ws.ID)
+	if err := w.focus(); err != nil {
+		return err
+	}
+	_, _, err := w.ws.wd.del(w.ws.Context(), "/session/%s/window", w.ws.ID)
 	return err
 }
 
 // Resize resizes the window to the specified size.
 func (w *Window) Resize(width, height int) error {
 	opt := map[string]interface{}{"width": width, "height": height}
-	_, _, err := w.ws.wd.post("/session/%s/window/%s/size", opt, w.ws.ID, w.ID)
+	if w.ws.w3c() {
+		if err := w.focus(); err != nil {
+			return err
+		}
+		_, _, err := w.ws.wd.post(w.ws.Context(), "/session/%s/window/rect", opt, w.ws.ID)
+		return err
+	}
+	_, _, err := w.ws.wd.post(w.ws.Context(), "/session/%s/window/%s/size", opt, w.ws.ID, w.ID)
 	return err
 }
 
 // Minimize minimizes the browser window.
 func (w *Window) Minimize() error {
-	_, _, err := w.ws.wd.post("/session/%s/window/%s/minimize", nil, w.ws.ID, w.ID)
+	if w.ws.w3c() {
+		if err := w.focus(); err != nil {
+			return err
+		}
+		_, _, err := w.ws.wd.post(w.ws.Context(), "/session/%s/window/minimize", nil, w.ws.ID)
+		return err
+	}
+	_, _, err := w.ws.wd.post(w.ws.Context(), "/session/%s/window/%s/minimize", nil, w.ws.ID, w.ID)
 	return err
 }
 
 // Maximize maximizes the browser window.
 func (w *Window) Maximize() error {
-	_, _, err := w.ws.wd.post("/session/%s/window/%s/maximize", nil, w.ws.ID, w.ID)
+	if w.ws.w3c() {
+		if err := w.focus(); err != nil {
+			return err
+		}
+		_, _, err := w.ws.wd.post(w.ws.Context(), "/session/%s/window/maximize", nil, w.ws.ID)
+		return err
+	}
+	_, _, err := w.ws.wd.post(w.ws.Context(), "/session/%s/window/%s/maximize", nil, w.ws.ID, w.ID)
 	return err
 }